@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/francescomari/nu/paths"
+	"github.com/francescomari/nu/transform"
+	"github.com/spf13/cobra"
+)
+
+var (
+	catHexDump bool
+	catIndex   string
+)
+
+func init() {
+	catCmd.Flags().BoolVarP(&catHexDump, "hexdump", "x", false, "Print binary values as a hex dump")
+	catCmd.Flags().StringVar(&catIndex, "index", "", "Index of the export, as built by the index command")
+	rootCmd.AddCommand(catCmd)
+}
+
+var catCmd = &cobra.Command{
+	Use:   "cat [path]",
+	Short: "Print the properties of a node and their values",
+	Long:  "Reads an export file from stdin and prints the properties of a node, or a single property, with their types and values on stdout. If an index is specified, stdin must be the indexed export file, and only the relevant subtree is read from it.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			properties []transform.Record
+			err        error
+		)
+
+		if catIndex != "" {
+			properties, err = catWithIndex(args[0])
+		} else {
+			properties, err = transform.LookupProperties(args[0], parseInput(os.Stdin))
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Reading properties: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, p := range properties {
+			fmt.Printf("%v (%v)\n", p.Name, p.Type)
+			for _, v := range p.Values {
+				if !v.Binary {
+					fmt.Printf("  - %v\n", strings.Replace(v.Data, "\n", "\n    ", -1))
+					continue
+				}
+				data, err := hex.DecodeString(v.Data)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Decoding binary value: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Printf("  - <binary, %v>\n", size(len(data)))
+				if !catHexDump {
+					continue
+				}
+				for _, line := range strings.SplitAfter(strings.TrimSuffix(hex.Dump(data), "\n"), "\n") {
+					fmt.Printf("    %v", line)
+				}
+				fmt.Println()
+			}
+		}
+	},
+}
+
+// catWithIndex looks up the properties at path by reading from stdin only the
+// node at path, or its parent, without their children.
+func catWithIndex(path string) ([]transform.Record, error) {
+	ix, err := readIndex(catIndex, os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := paths.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry, ok := ix.Lookup(p.String()); ok {
+		return transform.LookupProperties("/", ix.Node(os.Stdin, entry))
+	}
+
+	// Properties don't have same-name siblings.
+	if p.IsRoot() || p[len(p)-1].Index > 1 {
+		return nil, transform.ErrNodeNotFound
+	}
+
+	entry, ok := ix.Lookup(p.Parent().String())
+	if !ok {
+		return nil, transform.ErrNodeNotFound
+	}
+
+	return transform.LookupProperties(paths.Root.Child(p.Name(), 1).String(), ix.Node(os.Stdin, entry))
+}
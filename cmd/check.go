@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/francescomari/nu/schema"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkSchema string
+	checkNames  bool
+)
+
+func init() {
+	checkCmd.Flags().StringVar(&checkSchema, "schema", "", "Content model, as a YAML file")
+	checkCmd.Flags().BoolVar(&checkNames, "names", false, "Check that the names of nodes and properties are valid in JCR")
+	rootCmd.AddCommand(checkCmd)
+}
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Validate the content against a content model",
+	Long:  "Reads an export file from stdin and prints on stdout every node or property violating the content model in the schema file, or whose name is not valid in JCR, with its path and, for exports in the text format, its line. If both checks are requested, stdin is read twice. The command exits with a non-zero status if any violation is found.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if checkSchema == "" && !checkNames {
+			fmt.Fprintf(os.Stderr, "Missing schema: use the --schema or the --names flag\n")
+			os.Exit(1)
+		}
+
+		var (
+			violations int
+			input      io.ReadSeeker = os.Stdin
+			cleanup                  = func() {}
+		)
+
+		if checkSchema != "" {
+			f, err := os.Open(checkSchema)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Reading schema: %v\n", err)
+				os.Exit(1)
+			}
+
+			model, err := schema.ReadModel(f)
+			f.Close()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Reading schema: %v\n", err)
+				os.Exit(1)
+			}
+
+			if checkNames {
+				export, remove, err := seekableStdin()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Reading export: %v\n", err)
+					os.Exit(1)
+				}
+				input, cleanup = export, remove
+			}
+
+			violations += printViolations(schema.Check(model, parseInputLines(input)), cleanup)
+		}
+
+		if checkNames {
+			if checkSchema != "" {
+				if _, err := input.Seek(0, io.SeekStart); err != nil {
+					fmt.Fprintf(os.Stderr, "Reading export: %v\n", err)
+					cleanup()
+					os.Exit(1)
+				}
+			}
+			violations += printViolations(schema.CheckNames(parseInputLines(input)), cleanup)
+		}
+
+		cleanup()
+
+		if violations > 0 {
+			fmt.Fprintf(os.Stderr, "%v violations found\n", violations)
+			os.Exit(1)
+		}
+	},
+}
+
+// printViolations prints the violations on stdout and returns their number. If
+// an error is read, printViolations calls cleanup and exits.
+func printViolations(violations <-chan schema.Violation, cleanup func()) int {
+	var n int
+
+	for v := range violations {
+		if v.Err != nil {
+			fmt.Fprintf(os.Stderr, "Error at line %v: %v\n", v.Line, v.Err)
+			cleanup()
+			os.Exit(1)
+		}
+		n++
+		if v.Line > 0 {
+			fmt.Printf("line %v: %v: %v\n", v.Line, v.Path, v.Message)
+		} else {
+			fmt.Printf("%v: %v\n", v.Path, v.Message)
+		}
+	}
+
+	return n
+}
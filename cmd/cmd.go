@@ -2,13 +2,45 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 
+	"github.com/francescomari/nu/filter"
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/paths"
 	"github.com/spf13/cobra"
 )
 
+var (
+	rootNamespaces []string
+	rootRemapping  paths.Remapping
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringArrayVar(&rootNamespaces, "ns", nil, "Remap a namespace prefix in the input, in the form old=new")
+}
+
 var rootCmd = &cobra.Command{
 	Use: "nu",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if len(rootNamespaces) == 0 {
+			return
+		}
+		if !remapsInput(cmd) {
+			fmt.Fprintf(os.Stderr, "Invalid arguments: namespaces can't be remapped by the %v command\n", cmd.Name())
+			os.Exit(1)
+		}
+		if f := cmd.Flags().Lookup("index"); f != nil && f.Changed {
+			fmt.Fprintf(os.Stderr, "Invalid arguments: namespaces can't be remapped when using an index\n")
+			os.Exit(1)
+		}
+		remapping, err := paths.ParseRemapping(rootNamespaces)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid arguments: %v\n", err)
+			os.Exit(1)
+		}
+		rootRemapping = remapping
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.Help()
 	},
@@ -21,3 +53,37 @@ func Execute() {
 		os.Exit(1)
 	}
 }
+
+// remapsInput tells if the command reads an export with parseInput,
+// parseInputLines, or remapInput, and thus supports remapping namespaces.
+func remapsInput(cmd *cobra.Command) bool {
+	switch cmd {
+	case indexCmd, importFsCmd, shellCmd:
+		return false
+	default:
+		return true
+	}
+}
+
+// parseInput parses an export in any format, remapping namespaces if
+// requested.
+func parseInput(r io.Reader) <-chan parser.Cmd {
+	return remapInput(parser.ParseAny(r))
+}
+
+// remapInput remaps namespaces in a stream of commands, if requested.
+func remapInput(cmds <-chan parser.Cmd) <-chan parser.Cmd {
+	if len(rootRemapping) == 0 {
+		return cmds
+	}
+	return filter.RemapNamespaces(rootRemapping, cmds)
+}
+
+// parseInputLines is like parseInput, but annotates every command with its
+// line.
+func parseInputLines(r io.Reader) <-chan parser.LineCmd {
+	if len(rootRemapping) == 0 {
+		return parser.ParseAnyLines(r)
+	}
+	return filter.RemapNamespacesLines(rootRemapping, parser.ParseAnyLines(r))
+}
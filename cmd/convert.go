@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/serializer"
+	"github.com/spf13/cobra"
+)
+
+var convertParsers = map[string]func(io.Reader) <-chan parser.Cmd{
+	"auto":    parser.ParseAny,
+	"text":    parser.Parse,
+	"json":    parser.ParseJSON,
+	"sysview": parser.ParseSysView,
+	"bin":     parser.ParseBinary,
+}
+
+var convertSerializers = map[string]func(<-chan parser.Cmd, io.Writer) error{
+	"text":    serializer.Serialize,
+	"json":    serializer.SerializeJSON,
+	"ndjson":  serializer.SerializeNDJSON,
+	"sysview": serializer.SerializeSysView,
+	"bin":     serializer.SerializeBinary,
+}
+
+var (
+	convertFrom       string
+	convertTo         string
+	convertNamespaces []string
+)
+
+func init() {
+	convertCmd.Flags().StringVar(&convertFrom, "from", "auto", "Format of the input")
+	convertCmd.Flags().StringVar(&convertTo, "to", "text", "Format of the output")
+	convertCmd.Flags().StringArrayVar(&convertNamespaces, "namespace", nil, "Declare a namespace in Document View XML, in the form prefix=uri")
+	rootCmd.AddCommand(convertCmd)
+}
+
+var convertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert an export between formats",
+	Long:  "Reads an export file from stdin, converts it to a different format, and prints the result on stdout. When converting to Document View XML, the export is read twice: first to collect the namespace prefixes in use, then to convert it. If stdin is not a regular file, it is copied to a temporary file.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		parse, ok := convertParsers[convertFrom]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Invalid input format: %v. Supported formats: %v\n", convertFrom, convertParserNames())
+			os.Exit(1)
+		}
+		if convertTo == "docview" {
+			convertDocView(parse)
+			return
+		}
+		serialize, ok := convertSerializers[convertTo]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Invalid output format: %v. Supported formats: %v\n", convertTo, convertSerializerNames())
+			os.Exit(1)
+		}
+		if err := serialize(remapInput(parse(os.Stdin)), os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while serializing: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func convertDocView(parse func(io.Reader) <-chan parser.Cmd) {
+	declared, err := parseNamespaces(convertNamespaces)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid arguments: %v\n", err)
+		os.Exit(1)
+	}
+
+	export, cleanup, err := seekableStdin()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Reading export: %v\n", err)
+		os.Exit(1)
+	}
+
+	prefixes, err := serializer.ScanPrefixes(remapInput(parse(export)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while serializing: %v\n", err)
+		cleanup()
+		os.Exit(1)
+	}
+
+	namespaces := make(map[string]string)
+	for _, prefix := range prefixes {
+		uri, ok := declared[prefix]
+		if !ok {
+			uri, ok = serializer.BuiltinNamespaces[prefix]
+		}
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Invalid arguments: namespace prefix %v is used, declare it with --namespace %v=uri\n", prefix, prefix)
+			cleanup()
+			os.Exit(1)
+		}
+		namespaces[prefix] = uri
+	}
+
+	if _, err := export.Seek(0, io.SeekStart); err != nil {
+		fmt.Fprintf(os.Stderr, "Reading export: %v\n", err)
+		cleanup()
+		os.Exit(1)
+	}
+
+	err = serializer.SerializeDocView(remapInput(parse(export)), namespaces, os.Stdout)
+	cleanup()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while serializing: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// parseNamespaces parses a list of namespace declarations in the form
+// `prefix=uri`.
+func parseNamespaces(specs []string) (map[string]string, error) {
+	namespaces := make(map[string]string)
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid namespace declaration: %v", spec)
+		}
+		namespaces[parts[0]] = parts[1]
+	}
+	return namespaces, nil
+}
+
+func convertParserNames() []string {
+	names := make([]string, 0, len(convertParsers))
+	for name := range convertParsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func convertSerializerNames() []string {
+	names := make([]string, 0, len(convertSerializers)+1)
+	for name := range convertSerializers {
+		names = append(names, name)
+	}
+	names = append(names, "docview")
+	sort.Strings(names)
+	return names
+}
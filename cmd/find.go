@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/francescomari/nu/find"
+	"github.com/francescomari/nu/paths"
+	"github.com/francescomari/nu/transform"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(findCmd)
+}
+
+var findCmd = &cobra.Command{
+	Use:   "find [path] [expression]",
+	Short: "Print the paths of the nodes and properties matching an expression",
+	Long: `Reads an export file from stdin and prints on stdout the paths of the nodes and properties matching an expression, like the Unix find utility. If a path is specified, only the node at that path and its descendants are considered. The expression is composed of the following primaries:
+
+  -name GLOB           The name matches GLOB.
+  -path GLOB           The path matches GLOB. '*' matches '/' too.
+  -type node|property  The item is a node or a property.
+  -ptype TYPE          The item is a property of type TYPE.
+  -depth N             The path has N components.
+  -size N              The size of the values in the item is N bytes.
+  -children N          The item is a node with N children.
+  -has-property NAME   The item is a node with a property called NAME.
+
+Numeric arguments can be prefixed with '+' to mean more than N, or with '-' to mean less than N. The argument to -size can be suffixed with 'k', 'M', or 'G'. Primaries are combined with -a, -o, !, and grouped with parentheses. The size of a node is the size of the values of every property in its subtree. Since the size of a node depends on its subtree, a node is printed after its descendants.`,
+	DisableFlagParsing: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
+			cmd.Help()
+			return
+		}
+
+		root := paths.Root
+
+		if len(args) > 0 && !isFindOperator(args[0]) {
+			p, err := paths.Parse(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Parsing path: %v\n", err)
+				os.Exit(1)
+			}
+			root, args = p, args[1:]
+		}
+
+		predicate, err := find.Parse(args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Parsing expression: %v\n", err)
+			os.Exit(1)
+		}
+
+		prefix := root.String()
+
+		for item := range transform.Items(parseInput(os.Stdin)) {
+			if item.Err != nil {
+				fmt.Fprintf(os.Stderr, "Error at line %v: %v\n", item.Line, item.Err)
+				os.Exit(1)
+			}
+			if !paths.Contains(prefix, item.Path) {
+				continue
+			}
+			if predicate(item) {
+				fmt.Println(item.Path)
+			}
+		}
+	},
+}
+
+func isFindOperator(arg string) bool {
+	return strings.HasPrefix(arg, "-") || arg == "!" || arg == "(" || arg == ")"
+}
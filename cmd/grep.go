@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/francescomari/nu/transform"
+	"github.com/spf13/cobra"
+)
+
+var (
+	grepIgnoreCase bool
+	grepName       string
+	grepBinary     bool
+	grepCount      bool
+)
+
+func init() {
+	grepCmd.Flags().BoolVarP(&grepIgnoreCase, "ignore-case", "i", false, "Match case-insensitively")
+	grepCmd.Flags().StringVarP(&grepName, "name", "n", "", "Only search properties whose name matches a glob pattern")
+	grepCmd.Flags().BoolVarP(&grepBinary, "binary", "b", false, "Search the decoded payload of binary values too")
+	grepCmd.Flags().BoolVarP(&grepCount, "count", "c", false, "Only print the number of matches")
+	rootCmd.AddCommand(grepCmd)
+}
+
+var grepCmd = &cobra.Command{
+	Use:   "grep [pattern]",
+	Short: "Search property values with a regular expression",
+	Long:  "Reads an export file from stdin and prints on stdout the path of the property, the index of the value, and the quoted matching text, separated by tabs, for every match of a regular expression in the values of the properties.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		expr := args[0]
+		if grepIgnoreCase {
+			expr = "(?i)" + expr
+		}
+
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Parsing pattern: %v\n", err)
+			os.Exit(1)
+		}
+
+		options := transform.GrepOptions{
+			Pattern: pattern,
+			Name:    grepName,
+			Binary:  grepBinary,
+		}
+
+		var count int
+
+		for m := range transform.Grep(parseInput(os.Stdin), options) {
+			if m.Err != nil {
+				fmt.Fprintf(os.Stderr, "Error at line %v: %v\n", m.Line, m.Err)
+				os.Exit(1)
+			}
+			if grepCount {
+				count++
+				continue
+			}
+			fmt.Printf("%v\t%v\t%q\n", m.Path, m.Index, m.Match)
+		}
+
+		if grepCount {
+			fmt.Println(count)
+		}
+	},
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/serializer"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(importFsCmd)
+}
+
+var importFsCmd = &cobra.Command{
+	Use:   "import-fs [dir]",
+	Short: "Build an export from a directory tree",
+	Long:  "Reads a directory tree from the file system, converts it to an export, and prints the resulting export on stdout.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := serializer.Serialize(parser.ParseDir(args[0]), os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while serializing: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
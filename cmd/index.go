@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/francescomari/nu/index"
+	"github.com/francescomari/nu/paths"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(indexCmd)
+}
+
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Build an index for an export",
+	Long:  "Reads an export file in the text format from stdin, builds an index mapping every node to its position in the export, and prints the index on stdout. The index records the size of the export and, if stdin is a regular file, its modification time, so that commands reading the export with the index can detect if the index doesn't match the export.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ix, err := index.Build(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Building index: %v\n", err)
+			os.Exit(1)
+		}
+		if info, err := os.Stdin.Stat(); err == nil && info.Mode().IsRegular() {
+			ix.ModTime = info.ModTime()
+		}
+		if err := ix.Write(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Writing index: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// readIndex reads the index at the specified path, and verifies that it was
+// built for the export.
+func readIndex(path string, export *os.File) (*index.Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ix, err := index.Read(f)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := export.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := ix.Check(info); err != nil {
+		return nil, err
+	}
+
+	return ix, nil
+}
+
+// lookupIndex reads the index at the specified path, verifies that it was
+// built for the export on stdin, and returns the entry for the node at
+// nodePath. The node path is normalized before the lookup.
+func lookupIndex(path, nodePath string) (index.Entry, bool, error) {
+	ix, err := readIndex(path, os.Stdin)
+	if err != nil {
+		return index.Entry{}, false, err
+	}
+	p, err := paths.Parse(nodePath)
+	if err != nil {
+		return index.Entry{}, false, err
+	}
+	entry, ok := ix.Lookup(p.String())
+	return entry, ok, nil
+}
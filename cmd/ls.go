@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/francescomari/nu/index"
+	"github.com/francescomari/nu/transform"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lsLong  bool
+	lsIndex string
+)
+
+func init() {
+	lsCmd.Flags().BoolVarP(&lsLong, "long", "l", false, "Print details about children and properties")
+	lsCmd.Flags().StringVar(&lsIndex, "index", "", "Index of the export, as built by the index command")
+	rootCmd.AddCommand(lsCmd)
+}
+
+var lsCmd = &cobra.Command{
+	Use:   "ls [path]",
+	Short: "List the children and the properties of a node",
+	Long:  "Reads an export file from stdin and prints the children and the properties of a node on stdout. If an index is specified, stdin must be the indexed export file, and only the subtree of the node is read from it.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			listing *transform.Listing
+			err     error
+		)
+
+		if lsIndex != "" {
+			entry, ok, ierr := lookupIndex(lsIndex, args[0])
+			if ierr != nil {
+				fmt.Fprintf(os.Stderr, "Reading index: %v\n", ierr)
+				os.Exit(1)
+			}
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Listing node: %v\n", transform.ErrNodeNotFound)
+				os.Exit(1)
+			}
+			listing, err = transform.List("/", index.Subtree(os.Stdin, entry))
+		} else {
+			listing, err = transform.List(args[0], parseInput(os.Stdin))
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Listing node: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !lsLong {
+			for _, n := range listing.Nodes {
+				fmt.Printf("%v/\n", n.Name)
+			}
+			for _, p := range listing.Properties {
+				fmt.Println(p.Name)
+			}
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+
+		if len(listing.Nodes) > 0 {
+			fmt.Fprintf(w, "NODE\tCHILDREN\tPROPERTIES\n")
+			for _, n := range listing.Nodes {
+				fmt.Fprintf(w, "%v/\t%v\t%v\n", n.Name, n.Children, n.Properties)
+			}
+		}
+
+		if len(listing.Nodes) > 0 && len(listing.Properties) > 0 {
+			fmt.Fprintf(w, "\n")
+		}
+
+		if len(listing.Properties) > 0 {
+			fmt.Fprintf(w, "PROPERTY\tTYPE\tVALUES\tSIZE\n")
+			for _, p := range listing.Properties {
+				fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", p.Name, p.Type, p.Values, p.Size)
+			}
+		}
+
+		w.Flush()
+	},
+}
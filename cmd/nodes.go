@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/francescomari/nu/parser"
 	"github.com/francescomari/nu/transform"
 	"github.com/spf13/cobra"
 )
@@ -16,10 +15,10 @@ func init() {
 var nodesCmd = &cobra.Command{
 	Use:   "nodes [path]",
 	Short: "Print fully qualified node paths",
-	Long:  "Reads an export file from stdin and prints the fully qualified path of every node on stdout.",
+	Long:  "Reads an export file from stdin and prints the fully qualified path of every node on stdout. Every node after the first among its same-name siblings is printed with its index, so that every path addresses a single node.",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		for p := range transform.Nodes(parser.Parse(os.Stdin)) {
+		for p := range transform.Nodes(parseInput(os.Stdin)) {
 			if p.Err != nil {
 				fmt.Fprintf(os.Stderr, "Error at line %v: %v\n", p.Line, p.Err)
 				os.Exit(1)
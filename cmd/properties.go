@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/francescomari/nu/parser"
 	"github.com/francescomari/nu/transform"
 	"github.com/spf13/cobra"
 )
@@ -19,7 +18,7 @@ var propertiesCmd = &cobra.Command{
 	Long:  "Reads an export file from stdin and prints the type and the fully qualified path of every property on stdout.",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		for p := range transform.Properties(parser.Parse(os.Stdin)) {
+		for p := range transform.Properties(parseInput(os.Stdin)) {
 			if p.Err != nil {
 				fmt.Fprintf(os.Stderr, "Error at line %v: %v\n", p.Line, p.Err)
 				os.Exit(1)
@@ -5,26 +5,52 @@ import (
 	"os"
 
 	"github.com/francescomari/nu/filter"
+	"github.com/francescomari/nu/index"
 	"github.com/francescomari/nu/parser"
 	"github.com/francescomari/nu/serializer"
 	"github.com/spf13/cobra"
 )
 
+var pruneIndex string
+
 func init() {
+	pruneCmd.Flags().StringVar(&pruneIndex, "index", "", "Index of the export, as built by the index command")
 	rootCmd.AddCommand(pruneCmd)
 }
 
 var pruneCmd = &cobra.Command{
 	Use:   "prune [path]",
 	Short: "Remove a subtree from an export",
-	Long:  "Reads an export file from stdin, remove a subtree from it, and prints the resulting export on stdout.",
+	Long:  "Reads an export file from stdin, remove a subtree from it, and prints the resulting export on stdout. If an index is specified, stdin must be the indexed export file, and the removed subtree is never read from it.",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		out, err := filter.Prune(args[0], parser.Parse(os.Stdin))
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Invalid argument: %v\n", err)
-			os.Exit(1)
+		var out <-chan parser.Cmd
+
+		if pruneIndex != "" {
+			entry, ok, err := lookupIndex(pruneIndex, args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Reading index: %v\n", err)
+				os.Exit(1)
+			}
+			info, err := os.Stdin.Stat()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Reading export: %v\n", err)
+				os.Exit(1)
+			}
+			if ok {
+				out = index.Prune(os.Stdin, info.Size(), entry)
+			} else {
+				out = parser.Parse(os.Stdin)
+			}
+		} else {
+			pruned, err := filter.Prune(args[0], parseInput(os.Stdin))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid argument: %v\n", err)
+				os.Exit(1)
+			}
+			out = pruned
 		}
+
 		if err := serializer.Serialize(out, os.Stdout); err != nil {
 			fmt.Fprintf(os.Stderr, "Error while serializing: %v\n", err)
 			os.Exit(1)
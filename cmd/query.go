@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/francescomari/nu/query"
+	"github.com/spf13/cobra"
+)
+
+var (
+	queryExplain bool
+	queryHeader  bool
+)
+
+func init() {
+	queryCmd.Flags().BoolVar(&queryExplain, "explain", false, "Print the plan of the query instead of evaluating it")
+	queryCmd.Flags().BoolVarP(&queryHeader, "header", "H", false, "Print the names of the columns before the rows")
+	rootCmd.AddCommand(queryCmd)
+}
+
+var queryCmd = &cobra.Command{
+	Use:   "query [query]",
+	Short: "Evaluate a query over the content",
+	Long: `Reads an export file from stdin, evaluates a query over its nodes, and prints the resulting rows on stdout, one per line, with the values of the columns separated by tabs. A query has the following form:
+
+  SELECT columns [FROM 'path'] [WHERE condition]
+
+The columns can be '*', a list of fields, or a list of aggregates. A field is one of 'path', 'name', 'depth', 'size', or the name of a property, optionally enclosed in square brackets. The aggregates are 'count(*)', 'count(field)', and 'sum(field)'. The condition combines comparisons like 'field = 'value'', 'field > 10', 'field LIKE 'pattern'', and 'field IS NULL' with AND, OR, NOT, and parentheses. For example:
+
+  SELECT path FROM '/content' WHERE [jcr:primaryType] = 'cq:Page' AND [jcr:title] IS NULL`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		q, err := query.Parse(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Parsing query: %v\n", err)
+			os.Exit(1)
+		}
+
+		plan, err := query.NewPlan(q)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Planning query: %v\n", err)
+			os.Exit(1)
+		}
+
+		if queryExplain {
+			fmt.Print(plan)
+			return
+		}
+
+		if queryHeader {
+			fmt.Println(strings.Join(plan.Columns(), "\t"))
+		}
+
+		for row := range query.Evaluate(plan, parseInput(os.Stdin)) {
+			if row.Err != nil {
+				fmt.Fprintf(os.Stderr, "Error at line %v: %v\n", row.Line, row.Err)
+				os.Exit(1)
+			}
+			fmt.Println(strings.Join(row.Values, "\t"))
+		}
+	},
+}
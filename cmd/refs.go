@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/francescomari/nu/transform"
+	"github.com/spf13/cobra"
+)
+
+var (
+	refsTop   int
+	refsPrune string
+)
+
+func init() {
+	refsCmd.Flags().IntVar(&refsTop, "top", 10, "Number of most referenced nodes to print")
+	refsCmd.Flags().StringVar(&refsPrune, "prune", "", "Print the references broken by pruning the subtree at this path")
+	rootCmd.AddCommand(refsCmd)
+}
+
+var refsCmd = &cobra.Command{
+	Use:   "refs",
+	Short: "Check the integrity of references",
+	Long:  "Reads an export file from stdin and prints on stdout the references whose target doesn't exist and the most referenced nodes. References are the values of Reference and WeakReference properties, resolved through the jcr:uuid property of the nodes, and the values of Path properties. If a path to prune is specified, the command also prints the references that would be broken by removing the subtree at that path.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		refs, err := transform.CollectRefs(parseInput(os.Stdin))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Collecting references: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("References: %v\n", len(refs.References))
+
+		fmt.Printf("Dangling references:\n")
+		for _, ref := range refs.Dangling() {
+			fmt.Printf("  %v (%v) -> %v\n", ref.Source, ref.Type, ref.Value)
+		}
+
+		fmt.Printf("Most referenced nodes:\n")
+		for _, target := range refs.MostReferenced(refsTop) {
+			fmt.Printf("  %v: %v\n", target.Path, target.References)
+		}
+
+		if refsPrune == "" {
+			return
+		}
+
+		breaking, err := refs.Breaking(refsPrune)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid argument: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("References broken by pruning %v:\n", refsPrune)
+		for _, ref := range breaking {
+			fmt.Printf("  %v (%v) -> %v\n", ref.Source, ref.Type, ref.Target)
+		}
+	},
+}
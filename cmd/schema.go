@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/francescomari/nu/schema"
+	"github.com/spf13/cobra"
+)
+
+var (
+	schemaJSON     bool
+	schemaChildren int
+	schemaOptions  schema.InferOptions
+)
+
+func init() {
+	schemaCmd.Flags().BoolVar(&schemaJSON, "json", false, "Print the content model as JSON")
+	schemaCmd.Flags().IntVar(&schemaChildren, "children", 10, "Maximum number of child names printed for every group")
+	schemaCmd.Flags().StringArrayVarP(&schemaOptions.Patterns, "pattern", "g", nil, "Group nodes by a glob pattern matching their path instead of by primary type")
+	rootCmd.AddCommand(schemaCmd)
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Infer the content model",
+	Long:  "Reads an export file from stdin and prints on stdout the content model inferred from it. Nodes are grouped by primary type or, if patterns are specified, by the first pattern matching their path. For every group, the command prints which properties occur, their types, whether they are multi-valued, how often they occur, and the most frequent child names.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		inferred, err := schema.Infer(parseInput(os.Stdin), schemaOptions)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Inferring schema: %v\n", err)
+			os.Exit(1)
+		}
+
+		if schemaJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(inferred); err != nil {
+				fmt.Fprintf(os.Stderr, "Printing schema: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+		for i, g := range inferred.Groups {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+
+			name := g.Name
+			if name == "" {
+				name = "(no primary type)"
+			}
+
+			fmt.Fprintf(w, "%v: %v nodes\n", name, g.Nodes)
+
+			if len(g.Properties) > 0 {
+				fmt.Fprintf(w, "  Properties:\n")
+			}
+			for _, p := range g.Properties {
+				fmt.Fprintf(w, "    %v\t%v\t%v%%\n", p.Name, schemaTypes(p), p.Count*100/g.Nodes)
+			}
+
+			if len(g.Children) > 0 {
+				fmt.Fprintf(w, "  Children:\n")
+			}
+			for j, c := range g.Children {
+				if j == schemaChildren {
+					fmt.Fprintf(w, "    ... %v more names\n", len(g.Children)-j)
+					break
+				}
+				fmt.Fprintf(w, "    %v\t%v%%\n", c.Name, c.Count*100/g.Nodes)
+			}
+			if g.OtherChildren > 0 {
+				fmt.Fprintf(w, "    ... %v untracked children\n", g.OtherChildren)
+			}
+		}
+
+		w.Flush()
+	},
+}
+
+// schemaTypes formats the types of a property. Multi-valued properties are
+// marked with `[]`. If a property occurs with more than one type, every type
+// is followed by the number of its occurrences.
+func schemaTypes(p *schema.PropertyInfo) string {
+	suffix := ""
+	if p.Multiple {
+		suffix = "[]"
+	}
+
+	types := make([]string, 0, len(p.Types))
+	for t := range p.Types {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	if len(types) == 1 {
+		return types[0] + suffix
+	}
+
+	for i, t := range types {
+		types[i] = fmt.Sprintf("%v%v (%v)", t, suffix, p.Types[t])
+	}
+
+	return strings.Join(types, ", ")
+}
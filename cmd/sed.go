@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/francescomari/nu/filter"
+	"github.com/francescomari/nu/serializer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sedIgnoreCase   bool
+	sedSubstitution filter.Substitution
+)
+
+func init() {
+	sedCmd.Flags().BoolVarP(&sedIgnoreCase, "ignore-case", "i", false, "Match case-insensitively")
+	sedCmd.Flags().StringVarP(&sedSubstitution.Name, "name", "n", "", "Only change properties whose name matches a glob pattern")
+	sedCmd.Flags().StringVarP(&sedSubstitution.Type, "type", "t", "", "Only change properties of this type")
+	sedCmd.Flags().StringVarP(&sedSubstitution.Path, "path", "p", "", "Only change properties in the subtrees whose path matches a glob pattern")
+	rootCmd.AddCommand(sedCmd)
+}
+
+var sedCmd = &cobra.Command{
+	Use:   "sed [pattern] [replacement]",
+	Short: "Replace text in property values",
+	Long:  "Reads an export file from stdin, replaces every match of a regular expression in the values of the properties, and prints the resulting export on stdout. Inside the replacement, $1 or ${1} is replaced by the text of the first submatch, and so on. Binary values are never changed. The number of changed values is printed on stderr.",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		expr := args[0]
+		if sedIgnoreCase {
+			expr = "(?i)" + expr
+		}
+
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Parsing pattern: %v\n", err)
+			os.Exit(1)
+		}
+
+		sedSubstitution.Pattern = pattern
+		sedSubstitution.Replacement = args[1]
+
+		out, err := sedSubstitution.Apply(parseInput(os.Stdin))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid argument: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := serializer.Serialize(out, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while serializing: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(os.Stderr, "%v values changed\n", sedSubstitution.Changed)
+	},
+}
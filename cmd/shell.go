@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/francescomari/nu/index"
+	"github.com/francescomari/nu/shell"
+	"github.com/spf13/cobra"
+)
+
+var shellIndex string
+
+func init() {
+	shellCmd.Flags().StringVar(&shellIndex, "index", "", "Index of the export, as built by the index command")
+	rootCmd.AddCommand(shellCmd)
+}
+
+var shellCmd = &cobra.Command{
+	Use:   "shell [export]",
+	Short: "Navigate an export interactively",
+	Long:  "Opens an export file in the text format and starts an interactive shell to navigate it. If no index is specified, an index is built when the shell starts.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		export, err := os.Open(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Opening export: %v\n", err)
+			os.Exit(1)
+		}
+		defer export.Close()
+
+		var ix *index.Index
+
+		if shellIndex != "" {
+			ix, err = readIndex(shellIndex, export)
+		} else {
+			ix, err = index.Build(export)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Reading index: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := shell.Run(shell.New(ix, export, os.Stdout), os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
@@ -5,13 +5,24 @@ import (
 	"math"
 	"os"
 	"sort"
+	"strings"
 
-	"github.com/francescomari/nu/parser"
 	"github.com/francescomari/nu/transform"
 	"github.com/spf13/cobra"
 )
 
+// statsDominantTypes is the number of primary types printed for every depth
+// bucket.
+const statsDominantTypes = 3
+
+var (
+	statsTypes      bool
+	statsNamespaces bool
+)
+
 func init() {
+	statsCmd.Flags().BoolVarP(&statsTypes, "types", "t", false, "Print statistics about primary types and mixins")
+	statsCmd.Flags().BoolVarP(&statsNamespaces, "namespaces", "n", false, "Print statistics about the namespaces of nodes and properties")
 	rootCmd.AddCommand(statsCmd)
 }
 
@@ -21,7 +32,7 @@ var statsCmd = &cobra.Command{
 	Long:  "Reads an export file from stdin and prints statistics about the content on stdout.",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		stats, err := transform.Statistics(parser.Parse(os.Stdin))
+		stats, err := transform.Statistics(parseInput(os.Stdin))
 
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Computing statistics: %v\n", err)
@@ -57,9 +68,82 @@ var statsCmd = &cobra.Command{
 				logarithmicBucket{bucket, transform.StatsValueSizeBucketScale},
 				stats.ValuesPerSize[bucket])
 		}
+
+		if statsNamespaces {
+			fmt.Printf("Nodes per namespace:\n")
+			for _, ns := range sortedByCount(stats.NodesPerNamespace) {
+				fmt.Printf("  %v: %v\n", typeName(ns), stats.NodesPerNamespace[ns])
+			}
+
+			fmt.Printf("Properties per namespace:\n")
+			for _, ns := range sortedByCount(stats.PropertiesPerNamespace) {
+				fmt.Printf("  %v: %v\n", typeName(ns), stats.PropertiesPerNamespace[ns])
+			}
+		}
+
+		if !statsTypes {
+			return
+		}
+
+		fmt.Printf("Nodes per primary type:\n")
+		for _, typ := range sortedByCount(stats.NodesPerPrimaryType) {
+			fmt.Printf("  %v: %v nodes, %v\n",
+				typeName(typ),
+				stats.NodesPerPrimaryType[typ],
+				size(stats.DataPerPrimaryType[typ]))
+		}
+
+		fmt.Printf("Nodes per mixin:\n")
+		for _, mixin := range sortedByCount(stats.NodesPerMixin) {
+			fmt.Printf("  %v: %v nodes, %v\n",
+				mixin,
+				stats.NodesPerMixin[mixin],
+				size(stats.DataPerMixin[mixin]))
+		}
+
+		fmt.Printf("Primary types per depth:\n")
+		buckets := make([]int, 0, len(stats.PrimaryTypesPerDepth))
+		for bucket := range stats.PrimaryTypesPerDepth {
+			buckets = append(buckets, bucket)
+		}
+		sort.Ints(buckets)
+		for _, bucket := range buckets {
+			types := stats.PrimaryTypesPerDepth[bucket]
+			var total int
+			for _, n := range types {
+				total += n
+			}
+			var dominant []string
+			for i, typ := range sortedByCount(types) {
+				if i == statsDominantTypes {
+					break
+				}
+				dominant = append(dominant, fmt.Sprintf("%v %v%%", typeName(typ), types[typ]*100/total))
+			}
+			fmt.Printf("  %6v: %v\n",
+				linearBucket{bucket, transform.StatsTypeDepthBucketSize},
+				strings.Join(dominant, ", "))
+		}
 	},
 }
 
+// sortedByCount returns the keys of a map sorted by decreasing value, and then
+// by key.
+func sortedByCount(m map[string]int) []string {
+	keys := sortedStringKeys(m)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return m[keys[i]] > m[keys[j]]
+	})
+	return keys
+}
+
+func typeName(typ string) string {
+	if typ == "" {
+		return "(none)"
+	}
+	return typ
+}
+
 func sortedStringKeys(m map[string]int) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -2,32 +2,140 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 
 	"github.com/francescomari/nu/filter"
+	"github.com/francescomari/nu/index"
 	"github.com/francescomari/nu/parser"
 	"github.com/francescomari/nu/serializer"
+	"github.com/francescomari/nu/transform"
 	"github.com/spf13/cobra"
 )
 
+var (
+	subtreeIndex          string
+	subtreeWithReferences bool
+)
+
 func init() {
+	subtreeCmd.Flags().StringVar(&subtreeIndex, "index", "", "Index of the export, as built by the index command")
+	subtreeCmd.Flags().BoolVar(&subtreeWithReferences, "with-references", false, "Include the nodes referenced from the subtree, transitively, at their original paths")
 	rootCmd.AddCommand(subtreeCmd)
 }
 
 var subtreeCmd = &cobra.Command{
 	Use:   "subtree",
 	Short: "Shrinks the export to a subtree",
-	Long:  "Reads an export file from stdin, shrinks it to a specific subtree, and prints the resulting export on stdout.",
+	Long:  "Reads an export file from stdin, shrinks it to a specific subtree, and prints the resulting export on stdout. If an index is specified, stdin must be the indexed export file, and only the subtree is read from it. If references are included, the subtree and the subtrees of the nodes it references, transitively, are kept at their original paths, along with their ancestors, and stdin is read twice.",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		out, err := filter.Subtree(args[0], parser.Parse(os.Stdin))
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Invalid argument: %v\n", err)
+		var (
+			out     <-chan parser.Cmd
+			cleanup = func() {}
+		)
+
+		if subtreeIndex != "" && subtreeWithReferences {
+			fmt.Fprintf(os.Stderr, "Invalid arguments: an index can't be used when including references\n")
 			os.Exit(1)
 		}
-		if err := serializer.Serialize(out, os.Stdout); err != nil {
+
+		if subtreeWithReferences {
+			export, remove, err := seekableStdin()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Reading export: %v\n", err)
+				os.Exit(1)
+			}
+			cleanup = remove
+			closure, err := subtreeClosure(export, args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Collecting references: %v\n", err)
+				cleanup()
+				os.Exit(1)
+			}
+			out = closure
+		} else if subtreeIndex != "" {
+			entry, ok, err := lookupIndex(subtreeIndex, args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Reading index: %v\n", err)
+				os.Exit(1)
+			}
+			if !ok {
+				return
+			}
+			out = index.Subtree(os.Stdin, entry)
+		} else {
+			subtree, err := filter.Subtree(args[0], parseInput(os.Stdin))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid argument: %v\n", err)
+				os.Exit(1)
+			}
+			out = subtree
+		}
+
+		err := serializer.Serialize(out, os.Stdout)
+		cleanup()
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error while serializing: %v\n", err)
 			os.Exit(1)
 		}
 	},
 }
+
+// subtreeClosure reads the export twice. The first time, it collects the
+// references between the nodes. The second time, it filters the subtree at
+// path and the subtrees referenced from it.
+func subtreeClosure(export io.ReadSeeker, path string) (<-chan parser.Cmd, error) {
+	refs, err := transform.CollectRefs(parseInput(export))
+	if err != nil {
+		return nil, err
+	}
+
+	closure, err := refs.Closure(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := export.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return filter.Subtrees(closure, parseInput(export))
+}
+
+// seekableStdin returns stdin if it is a regular file. Otherwise, it copies
+// stdin to a temporary file and returns the temporary file. The returned
+// function removes the temporary file, if any.
+func seekableStdin() (*os.File, func(), error) {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if info.Mode().IsRegular() {
+		return os.Stdin, func() {}, nil
+	}
+
+	f, err := ioutil.TempFile("", "nu-")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cleanup := func() {
+		f.Close()
+		os.Remove(f.Name())
+	}
+
+	if _, err := io.Copy(f, os.Stdin); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	return f, cleanup, nil
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/francescomari/nu/serializer"
+	"github.com/spf13/cobra"
+)
+
+var treeOptions serializer.TreeOptions
+
+func init() {
+	treeCmd.Flags().IntVarP(&treeOptions.Depth, "depth", "L", 0, "Maximum depth of the printed nodes")
+	treeCmd.Flags().BoolVarP(&treeOptions.Properties, "properties", "p", false, "Print properties and their values")
+	treeCmd.Flags().BoolVarP(&treeOptions.Counts, "counts", "c", false, "Print the number of children of every node")
+	rootCmd.AddCommand(treeCmd)
+}
+
+var treeCmd = &cobra.Command{
+	Use:   "tree",
+	Short: "Print the content as a tree",
+	Long:  "Reads an export file from stdin and prints the content tree on stdout, like the Unix tree utility. The export is read twice: first to compute the shape of the tree, then to print it. If stdin is not a regular file, it is copied to a temporary file.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		export, cleanup, err := seekableStdin()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Reading export: %v\n", err)
+			os.Exit(1)
+		}
+
+		shape, err := serializer.ScanTree(parseInput(export), treeOptions)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Printing tree: %v\n", err)
+			cleanup()
+			os.Exit(1)
+		}
+
+		if _, err := export.Seek(0, io.SeekStart); err != nil {
+			fmt.Fprintf(os.Stderr, "Reading export: %v\n", err)
+			cleanup()
+			os.Exit(1)
+		}
+
+		err = serializer.SerializeTree(parseInput(export), shape, os.Stdout, treeOptions)
+		cleanup()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Printing tree: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
@@ -0,0 +1,72 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/paths"
+)
+
+// RemapNamespaces remaps the namespace prefixes of the names of the nodes and
+// of the properties in a stream of commands. The values of the properties of
+// type Name and Path, when expressed by V commands, are remapped as well.
+func RemapNamespaces(r paths.Remapping, commands <-chan parser.Cmd) <-chan parser.Cmd {
+	ch := make(chan parser.Cmd)
+	go func() {
+		defer close(ch)
+		n := namespaceRemapper{remapping: r}
+		for command := range commands {
+			ch <- n.remap(command)
+		}
+	}()
+	return ch
+}
+
+// RemapNamespacesLines is like RemapNamespaces, but for a stream of commands
+// annotated with their lines.
+func RemapNamespacesLines(r paths.Remapping, commands <-chan parser.LineCmd) <-chan parser.LineCmd {
+	ch := make(chan parser.LineCmd)
+	go func() {
+		defer close(ch)
+		n := namespaceRemapper{remapping: r}
+		for command := range commands {
+			ch <- parser.LineCmd{Cmd: n.remap(command.Cmd), Line: command.Line}
+		}
+	}()
+	return ch
+}
+
+type namespaceRemapper struct {
+	remapping paths.Remapping
+	// value remaps the values of the current property, if any.
+	value func(string) string
+}
+
+func (n *namespaceRemapper) remap(command parser.Cmd) parser.Cmd {
+	switch cmd := command.(type) {
+	case parser.C:
+		n.value = nil
+		return parser.C{Name: n.remapping.Name(cmd.Name)}
+	case parser.P:
+		n.value = n.valueRemapping(cmd.Type)
+		return parser.P{Type: cmd.Type, Name: n.remapping.Name(cmd.Name)}
+	case parser.V:
+		if n.value != nil {
+			return parser.V{Data: n.value(cmd.Data)}
+		}
+	case parser.Up:
+		n.value = nil
+	}
+	return command
+}
+
+func (n *namespaceRemapper) valueRemapping(t string) func(string) string {
+	switch {
+	case strings.EqualFold(t, "Name"):
+		return n.remapping.Name
+	case strings.EqualFold(t, "Path"):
+		return n.remapping.Path
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,68 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/paths"
+)
+
+func TestRemapNamespaces(t *testing.T) {
+	in := []parser.Cmd{
+		parser.R{},
+		parser.P{Type: "Name", Name: "old:type"},
+		parser.V{Data: "old:a"},
+		parser.V{Data: "other:b"},
+		parser.Up{}, // End of /[old:type]
+		parser.C{Name: "old:a"},
+		parser.P{Type: "Path", Name: "link"},
+		parser.V{Data: "/old:a/b/old:c"},
+		parser.Up{}, // End of /old:a[link]
+		parser.P{Type: "String", Name: "old:text"},
+		parser.V{Data: "old:a"},
+		parser.Up{}, // End of /old:a[old:text]
+		parser.C{Name: "{old}b"},
+		parser.Up{}, // End of /old:a/{old}b
+		parser.Up{}, // End of /old:a
+		parser.Up{}, // End of /
+	}
+
+	expected := []parser.Cmd{
+		parser.R{},
+		parser.P{Type: "Name", Name: "new:type"},
+		parser.V{Data: "new:a"},
+		parser.V{Data: "other:b"},
+		parser.Up{},
+		parser.C{Name: "new:a"},
+		parser.P{Type: "Path", Name: "link"},
+		parser.V{Data: "/new:a/b/new:c"},
+		parser.Up{},
+		parser.P{Type: "String", Name: "new:text"},
+		parser.V{Data: "old:a"},
+		parser.Up{},
+		parser.C{Name: "{old}b"},
+		parser.Up{},
+		parser.Up{},
+		parser.Up{},
+	}
+
+	inCh := make(chan parser.Cmd)
+
+	go func() {
+		defer close(inCh)
+		for _, cmd := range in {
+			inCh <- cmd
+		}
+	}()
+
+	var out []parser.Cmd
+
+	for cmd := range RemapNamespaces(paths.Remapping{"old": "new"}, inCh) {
+		out = append(out, cmd)
+	}
+
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("expected %v, got %v\n", expected, out)
+	}
+}
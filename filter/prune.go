@@ -5,8 +5,11 @@ import (
 	"github.com/francescomari/nu/paths"
 )
 
+// Prune filters a stream of commands into another stream of commands where the
+// tree rooted at `path` is excluded. The path can address same-name siblings,
+// like in `/a/b[2]`.
 func Prune(path string, commands <-chan parser.Cmd) (<-chan parser.Cmd, error) {
-	subtree, err := paths.Components(path)
+	subtree, err := paths.Parse(path)
 	if err != nil {
 		return nil, err
 	}
@@ -16,8 +19,9 @@ func Prune(path string, commands <-chan parser.Cmd) (<-chan parser.Cmd, error) {
 		defer close(ch)
 
 		var (
-			current []string
-			emit    bool
+			current  paths.Path
+			siblings paths.Siblings
+			emit     bool
 		)
 
 		for command := range commands {
@@ -25,18 +29,20 @@ func Prune(path string, commands <-chan parser.Cmd) (<-chan parser.Cmd, error) {
 			case parser.Err:
 				ch <- cmd
 			case parser.R:
-				emit = !isInSubtree(current, subtree)
+				siblings.Enter("")
+				emit = !subtree.Contains(current)
 				if emit {
 					ch <- cmd
 				}
 			case parser.C:
-				current = append(current, cmd.Name)
-				emit = !isInSubtree(current, subtree)
+				current = append(current, paths.Segment{Name: cmd.Name, Index: siblings.Enter(cmd.Name)})
+				emit = !subtree.Contains(current)
 				if emit {
 					ch <- cmd
 				}
 			case parser.P:
-				current = append(current, cmd.Name)
+				siblings.EnterProperty()
+				current = append(current, paths.Segment{Name: cmd.Name, Index: 1})
 				if emit {
 					ch <- cmd
 				}
@@ -44,10 +50,11 @@ func Prune(path string, commands <-chan parser.Cmd) (<-chan parser.Cmd, error) {
 				if emit {
 					ch <- cmd
 				}
+				siblings.Leave()
 				if len(current) > 0 {
 					current = current[:len(current)-1]
 				}
-				emit = !isInSubtree(current, subtree)
+				emit = !subtree.Contains(current)
 			default:
 				if emit {
 					ch <- cmd
@@ -232,3 +232,61 @@ func TestPruneLevelTwo(t *testing.T) {
 
 	assertCommandsEqual(t, expect, out)
 }
+
+func TestPruneSameNameSibling(t *testing.T) {
+	in := []parser.Cmd{
+		parser.R{},
+		parser.C{Name: "a"},
+		parser.P{Type: "string", Name: "p"},
+		parser.V{Data: "1"},
+		parser.Up{}, // End of /a[p]
+		parser.Up{}, // End of /a
+		parser.C{Name: "a"},
+		parser.P{Type: "string", Name: "p"},
+		parser.V{Data: "2"},
+		parser.Up{}, // End of /a[2][p]
+		parser.Up{}, // End of /a[2]
+		parser.C{Name: "a"},
+		parser.P{Type: "string", Name: "p"},
+		parser.V{Data: "3"},
+		parser.Up{}, // End of /a[3][p]
+		parser.Up{}, // End of /a[3]
+		parser.Up{}, // End of /
+	}
+
+	inCh := make(chan parser.Cmd)
+
+	go func() {
+		defer close(inCh)
+		for _, cmd := range in {
+			inCh <- cmd
+		}
+	}()
+
+	outCh, err := Prune("/a[2]", inCh)
+	if err != nil {
+		t.Fatalf("Prune: %v\n", err)
+	}
+
+	var out []parser.Cmd
+	for cmd := range outCh {
+		out = append(out, cmd)
+	}
+
+	expect := []parser.Cmd{
+		parser.R{},
+		parser.C{Name: "a"},
+		parser.P{Type: "string", Name: "p"},
+		parser.V{Data: "1"},
+		parser.Up{}, // End of /a[p]
+		parser.Up{}, // End of /a
+		parser.C{Name: "a"},
+		parser.P{Type: "string", Name: "p"},
+		parser.V{Data: "3"},
+		parser.Up{}, // End of /a[3][p]
+		parser.Up{}, // End of /a[3]
+		parser.Up{}, // End of /
+	}
+
+	assertCommandsEqual(t, expect, out)
+}
@@ -0,0 +1,115 @@
+package filter
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/paths"
+)
+
+// Substitution describes a substitution of the values of the properties, like
+// the `s` command of the Unix `sed` utility.
+type Substitution struct {
+	// Pattern is the regular expression matched against the values.
+	Pattern *regexp.Regexp
+	// Replacement is the replacement for every match of Pattern. Inside
+	// Replacement, `$` signs are interpreted as in regexp.Regexp.Expand.
+	Replacement string
+	// Name, if not empty, is a glob pattern restricting the substitution to
+	// the properties whose name matches it.
+	Name string
+	// Type, if not empty, restricts the substitution to the properties of
+	// this type. Type is compared case-insensitively.
+	Type string
+	// Path, if not empty, is a glob pattern restricting the substitution to
+	// the properties of the nodes whose path, or the path of any of their
+	// ancestors, matches it.
+	Path string
+	// Changed is the number of values changed by the substitution. Changed is
+	// final when the stream returned by Apply is closed.
+	Changed int
+}
+
+// Apply applies the substitution to the values expressed by V commands in a
+// stream of commands. Values expressed by X commands are never changed.
+func (s *Substitution) Apply(commands <-chan parser.Cmd) (<-chan parser.Cmd, error) {
+	if _, err := path.Match(s.Name, ""); err != nil {
+		return nil, fmt.Errorf("invalid name pattern: %v", err)
+	}
+	if _, err := path.Match(s.Path, ""); err != nil {
+		return nil, fmt.Errorf("invalid path pattern: %v", err)
+	}
+
+	ch := make(chan parser.Cmd)
+	go func() {
+		defer close(ch)
+
+		var (
+			current  paths.Path
+			siblings paths.Siblings
+			// matches tells, for every node in current, if the path of
+			// the node or of one of its ancestors matches s.Path.
+			matches []bool
+			active  bool
+		)
+
+		for command := range commands {
+			switch cmd := command.(type) {
+			case parser.R:
+				current = nil
+				siblings.Enter("")
+				matches = []bool{s.matchesPath(nil, false)}
+			case parser.C:
+				current = append(current, paths.Segment{Name: cmd.Name, Index: siblings.Enter(cmd.Name)})
+				matches = append(matches, s.matchesPath(current, len(matches) > 0 && matches[len(matches)-1]))
+			case parser.P:
+				active = len(matches) > 0 && matches[len(matches)-1] && s.matchesProperty(cmd)
+				siblings.EnterProperty()
+				current = append(current, paths.Segment{Name: cmd.Name, Index: 1})
+				matches = append(matches, false)
+			case parser.V:
+				if active {
+					if data := s.Pattern.ReplaceAllString(cmd.Data, s.Replacement); data != cmd.Data {
+						s.Changed++
+						cmd.Data = data
+					}
+				}
+				ch <- cmd
+				continue
+			case parser.Up:
+				active = false
+				siblings.Leave()
+				if len(current) > 0 {
+					current = current[:len(current)-1]
+				}
+				if len(matches) > 0 {
+					matches = matches[:len(matches)-1]
+				}
+			}
+			ch <- command
+		}
+	}()
+	return ch, nil
+}
+
+func (s *Substitution) matchesPath(current paths.Path, parent bool) bool {
+	if s.Path == "" || parent {
+		return true
+	}
+	ok, _ := path.Match(s.Path, current.String())
+	return ok
+}
+
+func (s *Substitution) matchesProperty(p parser.P) bool {
+	if s.Type != "" && !strings.EqualFold(p.Type, s.Type) {
+		return false
+	}
+	if s.Name == "" {
+		return true
+	}
+	ok, _ := path.Match(s.Name, p.Name)
+	return ok
+}
@@ -0,0 +1,89 @@
+package filter
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+func TestSubstitution(t *testing.T) {
+	in := []parser.Cmd{
+		parser.R{},
+		parser.P{Type: "String", Name: "url"},
+		parser.V{Data: "http://old.example.com/a"},
+		parser.Up{}, // End of /[url]
+		parser.C{Name: "a"},
+		parser.P{Type: "String", Name: "url"},
+		parser.V{Data: "http://old.example.com/b"},
+		parser.V{Data: "http://new.example.com/c"},
+		parser.Up{}, // End of /a[url]
+		parser.P{Type: "Name", Name: "link"},
+		parser.V{Data: "http://old.example.com/d"},
+		parser.Up{}, // End of /a[link]
+		parser.P{Type: "Binary", Name: "data"},
+		parser.X{Data: "6f6c64"},
+		parser.Up{}, // End of /a[data]
+		parser.C{Name: "b"},
+		parser.P{Type: "String", Name: "url"},
+		parser.V{Data: "http://old.example.com/e"},
+		parser.Up{}, // End of /a/b[url]
+		parser.Up{}, // End of /a/b
+		parser.Up{}, // End of /a
+		parser.Up{}, // End of /
+	}
+
+	tests := []struct {
+		name    string
+		s       Substitution
+		changed []int
+	}{
+		{"everything", Substitution{}, []int{2, 6, 10, 17}},
+		{"name", Substitution{Name: "u*"}, []int{2, 6, 17}},
+		{"type", Substitution{Type: "name"}, []int{10}},
+		{"path", Substitution{Path: "/a"}, []int{6, 10, 17}},
+		{"path pattern", Substitution{Path: "/*/b"}, []int{17}},
+	}
+
+	for _, tt := range tests {
+		tt.s.Pattern = regexp.MustCompile(`//old\.(\w+)`)
+		tt.s.Replacement = "//new.$1"
+
+		inCh := make(chan parser.Cmd)
+
+		go func() {
+			defer close(inCh)
+			for _, cmd := range in {
+				inCh <- cmd
+			}
+		}()
+
+		outCh, err := tt.s.Apply(inCh)
+		if err != nil {
+			t.Fatalf("%v: Apply: %v\n", tt.name, err)
+		}
+
+		var out []parser.Cmd
+		for cmd := range outCh {
+			out = append(out, cmd)
+		}
+
+		expected := append([]parser.Cmd(nil), in...)
+		for _, i := range tt.changed {
+			expected[i] = parser.V{Data: regexp.MustCompile(`//old\.`).ReplaceAllString(in[i].(parser.V).Data, "//new.")}
+		}
+
+		assertCommandsEqual(t, expected, out)
+
+		if tt.s.Changed != len(tt.changed) {
+			t.Errorf("%v: expected %v changed values, got %v\n", tt.name, len(tt.changed), tt.s.Changed)
+		}
+	}
+}
+
+func TestSubstitutionInvalidPattern(t *testing.T) {
+	s := Substitution{Pattern: regexp.MustCompile("a"), Path: "["}
+	if _, err := s.Apply(make(chan parser.Cmd)); err == nil {
+		t.Errorf("expected error, got nil\n")
+	}
+}
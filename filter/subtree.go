@@ -9,9 +9,10 @@ import (
 
 // Subtree filters a stream of commands into another stream of command where the
 // tree rooted at `path` is the new root. Every part of the input commands not
-// rooted at `path` is excluded from the output commands.
+// rooted at `path` is excluded from the output commands. The path can address
+// same-name siblings, like in `/a/b[2]`.
 func Subtree(path string, commands <-chan parser.Cmd) (<-chan parser.Cmd, error) {
-	subtree, err := paths.Components(path)
+	subtree, err := paths.Parse(path)
 	if err != nil {
 		return nil, fmt.Errorf("splitting path components: %v", err)
 	}
@@ -21,8 +22,9 @@ func Subtree(path string, commands <-chan parser.Cmd) (<-chan parser.Cmd, error)
 		defer close(ch)
 
 		var (
-			current []string
-			send    bool
+			current  paths.Path
+			siblings paths.Siblings
+			send     bool
 		)
 
 		for command := range commands {
@@ -30,22 +32,24 @@ func Subtree(path string, commands <-chan parser.Cmd) (<-chan parser.Cmd, error)
 			case parser.Err:
 				ch <- cmd
 			case parser.R:
-				send = isInSubtree(current, subtree)
+				siblings.Enter("")
+				send = subtree.Contains(current)
 				if send {
 					ch <- cmd
 				}
 			case parser.C:
-				current = append(current, cmd.Name)
+				current = append(current, paths.Segment{Name: cmd.Name, Index: siblings.Enter(cmd.Name)})
 				if send {
 					ch <- cmd
 					continue
 				}
-				send = isInSubtree(current, subtree)
+				send = subtree.Contains(current)
 				if send {
 					ch <- parser.R{}
 				}
 			case parser.P:
-				current = append(current, cmd.Name)
+				siblings.EnterProperty()
+				current = append(current, paths.Segment{Name: cmd.Name, Index: 1})
 				if send {
 					ch <- cmd
 				}
@@ -53,10 +57,11 @@ func Subtree(path string, commands <-chan parser.Cmd) (<-chan parser.Cmd, error)
 				if send {
 					ch <- cmd
 				}
+				siblings.Leave()
 				if len(current) > 0 {
 					current = current[:len(current)-1]
 				}
-				send = isInSubtree(current, subtree)
+				send = subtree.Contains(current)
 			default:
 				if send {
 					ch <- cmd
@@ -66,15 +71,3 @@ func Subtree(path string, commands <-chan parser.Cmd) (<-chan parser.Cmd, error)
 	}()
 	return ch, nil
 }
-
-func isInSubtree(path, subtree []string) bool {
-	if len(path) < len(subtree) {
-		return false
-	}
-	for i := range subtree {
-		if subtree[i] != path[i] {
-			return false
-		}
-	}
-	return true
-}
@@ -233,6 +233,87 @@ func commandsEqual(a, b []parser.Cmd) bool {
 	return true
 }
 
+func TestSameNameSiblingSubtree(t *testing.T) {
+	in := []parser.Cmd{
+		parser.R{},
+		parser.C{Name: "a"},
+		parser.C{Name: "b"},
+		parser.P{Type: "string", Name: "p"},
+		parser.V{Data: "1"},
+		parser.Up{}, // End of /a/b[p]
+		parser.Up{}, // End of /a/b
+		parser.C{Name: "b"},
+		parser.P{Type: "string", Name: "p"},
+		parser.V{Data: "2"},
+		parser.Up{}, // End of /a/b[2][p]
+		parser.Up{}, // End of /a/b[2]
+		parser.Up{}, // End of /a
+		parser.C{Name: "a"},
+		parser.C{Name: "b"},
+		parser.Up{}, // End of /a[2]/b
+		parser.C{Name: "b"},
+		parser.P{Type: "string", Name: "p"},
+		parser.V{Data: "3"},
+		parser.Up{}, // End of /a[2]/b[2][p]
+		parser.Up{}, // End of /a[2]/b[2]
+		parser.Up{}, // End of /a[2]
+		parser.Up{}, // End of /
+	}
+
+	tests := []struct {
+		path   string
+		expect []parser.Cmd
+	}{
+		{
+			"/a/b[2]",
+			[]parser.Cmd{
+				parser.R{},
+				parser.P{Type: "string", Name: "p"},
+				parser.V{Data: "2"},
+				parser.Up{},
+				parser.Up{},
+			},
+		},
+		{
+			"/a[2]/b[2]",
+			[]parser.Cmd{
+				parser.R{},
+				parser.P{Type: "string", Name: "p"},
+				parser.V{Data: "3"},
+				parser.Up{},
+				parser.Up{},
+			},
+		},
+		{
+			"/a/b[3]",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		inCh := make(chan parser.Cmd)
+
+		go func() {
+			defer close(inCh)
+			for _, cmd := range in {
+				inCh <- cmd
+			}
+		}()
+
+		outCh, err := Subtree(tt.path, inCh)
+		if err != nil {
+			t.Fatalf("Subtree: %v\n", err)
+		}
+
+		var out []parser.Cmd
+		for cmd := range outCh {
+			out = append(out, cmd)
+		}
+
+		assertCommandsEqual(t, tt.expect, out)
+	}
+}
+
 func assertCommandsEqual(t *testing.T, expected, got []parser.Cmd) {
 	t.Helper()
 	if commandsEqual(expected, got) {
@@ -0,0 +1,98 @@
+package filter
+
+import (
+	"fmt"
+
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/paths"
+)
+
+// Subtrees filters a stream of commands into another stream of commands
+// containing only the trees rooted at `subtreePaths`, at their original paths.
+// The ancestors of every subtree are included with their properties, but
+// without any child not leading to a subtree. The paths can address same-name
+// siblings, like in `/a/b[2]`.
+func Subtrees(subtreePaths []string, commands <-chan parser.Cmd) (<-chan parser.Cmd, error) {
+	var subtrees []paths.Path
+
+	for _, path := range subtreePaths {
+		subtree, err := paths.Parse(path)
+		if err != nil {
+			return nil, fmt.Errorf("splitting path components: %v", err)
+		}
+		subtrees = append(subtrees, subtree)
+	}
+
+	ch := make(chan parser.Cmd)
+	go func() {
+		defer close(ch)
+
+		var (
+			current  paths.Path
+			siblings paths.Siblings
+			// skip is the number of open nodes, or properties, in an
+			// excluded subtree.
+			skip int
+		)
+
+		for command := range commands {
+			if skip > 0 {
+				switch command.(type) {
+				case parser.C, parser.P:
+					skip++
+				case parser.Up:
+					skip--
+				case parser.Err:
+					ch <- command
+				}
+				continue
+			}
+
+			switch cmd := command.(type) {
+			case parser.R:
+				siblings.Enter("")
+				ch <- cmd
+			case parser.C:
+				current = append(current, paths.Segment{Name: cmd.Name, Index: siblings.Enter(cmd.Name)})
+				if isInAnySubtree(current, subtrees) || isAncestorOfAnySubtree(current, subtrees) {
+					ch <- cmd
+				} else {
+					current = current[:len(current)-1]
+					siblings.Leave()
+					skip++
+				}
+			case parser.Up:
+				ch <- cmd
+				siblings.Leave()
+				if len(current) > 0 {
+					current = current[:len(current)-1]
+				}
+			case parser.P:
+				siblings.EnterProperty()
+				current = append(current, paths.Segment{Name: cmd.Name, Index: 1})
+				ch <- cmd
+			default:
+				ch <- cmd
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func isInAnySubtree(path paths.Path, subtrees []paths.Path) bool {
+	for _, subtree := range subtrees {
+		if subtree.Contains(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func isAncestorOfAnySubtree(path paths.Path, subtrees []paths.Path) bool {
+	for _, subtree := range subtrees {
+		if path.Contains(subtree) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+func TestSubtrees(t *testing.T) {
+	in := []parser.Cmd{
+		parser.R{},
+		parser.P{Type: "string", Name: "p"},
+		parser.V{Data: "root"},
+		parser.Up{}, // End of /[p]
+		parser.C{Name: "a"},
+		parser.P{Type: "string", Name: "q"},
+		parser.V{Data: "a"},
+		parser.Up{}, // End of /a[q]
+		parser.C{Name: "b"},
+		parser.C{Name: "c"},
+		parser.Up{}, // End of /a/b/c
+		parser.Up{}, // End of /a/b
+		parser.C{Name: "d"},
+		parser.P{Type: "string", Name: "r"},
+		parser.V{Data: "d"},
+		parser.Up{}, // End of /a/d[r]
+		parser.Up{}, // End of /a/d
+		parser.Up{}, // End of /a
+		parser.C{Name: "e"},
+		parser.C{Name: "f"},
+		parser.Up{}, // End of /e/f
+		parser.Up{}, // End of /e
+		parser.C{Name: "g"},
+		parser.Up{}, // End of /g
+		parser.Up{}, // End of /
+	}
+
+	expected := []parser.Cmd{
+		parser.R{},
+		parser.P{Type: "string", Name: "p"},
+		parser.V{Data: "root"},
+		parser.Up{}, // End of /[p]
+		parser.C{Name: "a"},
+		parser.P{Type: "string", Name: "q"},
+		parser.V{Data: "a"},
+		parser.Up{}, // End of /a[q]
+		parser.C{Name: "b"},
+		parser.C{Name: "c"},
+		parser.Up{}, // End of /a/b/c
+		parser.Up{}, // End of /a/b
+		parser.Up{}, // End of /a
+		parser.C{Name: "e"},
+		parser.C{Name: "f"},
+		parser.Up{}, // End of /e/f
+		parser.Up{}, // End of /e
+		parser.Up{}, // End of /
+	}
+
+	inCh := make(chan parser.Cmd)
+
+	go func() {
+		defer close(inCh)
+		for _, cmd := range in {
+			inCh <- cmd
+		}
+	}()
+
+	outCh, err := Subtrees([]string{"/a/b", "/e"}, inCh)
+	if err != nil {
+		t.Fatalf("Subtrees: %v\n", err)
+	}
+
+	var out []parser.Cmd
+	for cmd := range outCh {
+		out = append(out, cmd)
+	}
+
+	assertCommandsEqual(t, expected, out)
+}
+
+func TestSubtreesSameNameSiblings(t *testing.T) {
+	in := []parser.Cmd{
+		parser.R{},
+		parser.C{Name: "a"},
+		parser.C{Name: "b"},
+		parser.Up{}, // End of /a/b
+		parser.Up{}, // End of /a
+		parser.C{Name: "a"},
+		parser.C{Name: "c"},
+		parser.Up{}, // End of /a[2]/c
+		parser.Up{}, // End of /a[2]
+		parser.C{Name: "a"},
+		parser.Up{}, // End of /a[3]
+		parser.Up{}, // End of /
+	}
+
+	expected := []parser.Cmd{
+		parser.R{},
+		parser.C{Name: "a"},
+		parser.C{Name: "c"},
+		parser.Up{}, // End of /a[2]/c
+		parser.Up{}, // End of /a[2]
+		parser.Up{}, // End of /
+	}
+
+	inCh := make(chan parser.Cmd)
+
+	go func() {
+		defer close(inCh)
+		for _, cmd := range in {
+			inCh <- cmd
+		}
+	}()
+
+	outCh, err := Subtrees([]string{"/a[2]"}, inCh)
+	if err != nil {
+		t.Fatalf("Subtrees: %v\n", err)
+	}
+
+	var out []parser.Cmd
+	for cmd := range outCh {
+		out = append(out, cmd)
+	}
+
+	assertCommandsEqual(t, expected, out)
+}
@@ -0,0 +1,297 @@
+package find
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/francescomari/nu/transform"
+)
+
+var (
+	// ErrInvalidExpression is returned when an expression can't be parsed.
+	ErrInvalidExpression = errors.New("invalid expression")
+)
+
+// Predicate reports whether an item matches an expression.
+type Predicate func(transform.Item) bool
+
+// Parse parses an expression in the style of the Unix `find` utility and
+// returns a Predicate evaluating it. The expression is composed of the
+// following primaries:
+//
+//	-name GLOB           The name matches GLOB.
+//	-path GLOB           The path matches GLOB. `*` matches `/` too.
+//	-type node|property  The item is a node or a property.
+//	-ptype TYPE          The item is a property of type TYPE.
+//	-depth N             The path has N components.
+//	-size N              The size of the values in the item is N bytes.
+//	-children N          The item is a node with N children.
+//	-has-property NAME   The item is a node with a property called NAME.
+//
+// Numeric arguments can be prefixed with `+` to mean more than N, or with `-`
+// to mean less than N. The argument to -size can be suffixed with `k`, `M`, or
+// `G` for kibibytes, mebibytes, and gibibytes. Primaries are combined with
+// `-a` or `-and` (implicit when omitted), `-o` or `-or`, `!` or `-not`, and
+// grouped with parentheses. An empty expression matches every item.
+func Parse(args []string) (Predicate, error) {
+	p := parser{args: args}
+
+	if len(args) == 0 {
+		return func(transform.Item) bool { return true }, nil
+	}
+
+	predicate, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.args) {
+		return nil, fmt.Errorf("%v: unexpected %v", ErrInvalidExpression, p.args[p.pos])
+	}
+
+	return predicate, nil
+}
+
+type parser struct {
+	args []string
+	pos  int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.args) {
+		return p.args[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) next() (string, error) {
+	if p.pos >= len(p.args) {
+		return "", fmt.Errorf("%v: unexpected end of expression", ErrInvalidExpression)
+	}
+	p.pos++
+	return p.args[p.pos-1], nil
+}
+
+func (p *parser) parseOr() (Predicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == "-o" || p.peek() == "-or" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = or(left, right)
+	}
+
+	return left, nil
+}
+
+func or(left, right Predicate) Predicate {
+	return func(i transform.Item) bool { return left(i) || right(i) }
+}
+
+func (p *parser) parseAnd() (Predicate, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch p.peek() {
+		case "", "-o", "-or", ")":
+			return left, nil
+		case "-a", "-and":
+			p.pos++
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = and(left, right)
+	}
+}
+
+func and(left, right Predicate) Predicate {
+	return func(i transform.Item) bool { return left(i) && right(i) }
+}
+
+func (p *parser) parseUnary() (Predicate, error) {
+	token, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case "!", "-not":
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(i transform.Item) bool { return !operand(i) }, nil
+	case "(":
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if token, err := p.next(); err != nil || token != ")" {
+			return nil, fmt.Errorf("%v: missing )", ErrInvalidExpression)
+		}
+		return inner, nil
+	}
+
+	argument, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case "-name":
+		if _, err := path.Match(argument, ""); err != nil {
+			return nil, fmt.Errorf("%v: %v", ErrInvalidExpression, err)
+		}
+		return func(i transform.Item) bool {
+			ok, _ := path.Match(argument, i.Name)
+			return ok
+		}, nil
+	case "-path":
+		re, err := globToRegexp(argument)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", ErrInvalidExpression, err)
+		}
+		return func(i transform.Item) bool { return re.MatchString(i.Path) }, nil
+	case "-type":
+		var kind transform.RecordKind
+		switch argument {
+		case "node", "n":
+			kind = transform.NodeRecord
+		case "property", "p":
+			kind = transform.PropertyRecord
+		default:
+			return nil, fmt.Errorf("%v: unknown type %v", ErrInvalidExpression, argument)
+		}
+		return func(i transform.Item) bool { return i.Kind == kind }, nil
+	case "-ptype":
+		return func(i transform.Item) bool {
+			return i.Kind == transform.PropertyRecord && strings.EqualFold(i.Type, argument)
+		}, nil
+	case "-depth":
+		compare, err := parseComparison(argument, false)
+		if err != nil {
+			return nil, err
+		}
+		return func(i transform.Item) bool { return compare(int64(i.Depth)) }, nil
+	case "-size":
+		compare, err := parseComparison(argument, true)
+		if err != nil {
+			return nil, err
+		}
+		return func(i transform.Item) bool { return compare(i.Size) }, nil
+	case "-children":
+		compare, err := parseComparison(argument, false)
+		if err != nil {
+			return nil, err
+		}
+		return func(i transform.Item) bool {
+			return i.Kind == transform.NodeRecord && compare(int64(i.Children))
+		}, nil
+	case "-has-property":
+		return func(i transform.Item) bool {
+			for _, name := range i.Properties {
+				if name == argument {
+					return true
+				}
+			}
+			return false
+		}, nil
+	default:
+		return nil, fmt.Errorf("%v: unknown primary %v", ErrInvalidExpression, token)
+	}
+}
+
+// parseComparison parses a numeric argument, optionally prefixed by `+` or `-`
+// and, if units is true, optionally suffixed by a unit.
+func parseComparison(argument string, units bool) (func(int64) bool, error) {
+	s := argument
+
+	var sign byte
+	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+		sign, s = s[0], s[1:]
+	}
+
+	multiplier := int64(1)
+	if units && s != "" {
+		switch s[len(s)-1] {
+		case 'k', 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		}
+		if multiplier > 1 {
+			s = s[:len(s)-1]
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%v: invalid number %v", ErrInvalidExpression, argument)
+	}
+	n *= multiplier
+
+	switch sign {
+	case '+':
+		return func(v int64) bool { return v > n }, nil
+	case '-':
+		return func(v int64) bool { return v < n }, nil
+	default:
+		return func(v int64) bool { return v == n }, nil
+	}
+}
+
+// globToRegexp converts a glob pattern to a regular expression matching whole
+// strings, where `*` matches any sequence of characters, including `/`.
+func globToRegexp(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+
+	b.WriteString("^")
+
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return nil, errors.New("unterminated character class")
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.Replace(class, `\`, `\\`, -1) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
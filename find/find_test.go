@@ -0,0 +1,92 @@
+package find
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/transform"
+)
+
+func TestParse(t *testing.T) {
+	var (
+		root = transform.Item{Kind: transform.NodeRecord, Path: "/", Size: 3, Children: 1, Properties: []string{"jcr:primaryType"}}
+		node = transform.Item{Kind: transform.NodeRecord, Path: "/content/site", Name: "site", Depth: 2, Children: 3}
+		prop = transform.Item{Kind: transform.PropertyRecord, Path: "/content/site/data", Name: "data", Depth: 3, Size: 2048, Type: "Binary", Values: 1}
+		utf8 = transform.Item{Kind: transform.NodeRecord, Path: "/cöntent/x", Name: "x", Depth: 2}
+	)
+
+	tests := []struct {
+		expression string
+		item       transform.Item
+		match      bool
+	}{
+		{"", node, true},
+		{"-name site", node, true},
+		{"-name s*", node, true},
+		{"-name s*", prop, false},
+		{"-path /content/*", prop, true},
+		{"-path /content/*/data", prop, true},
+		{"-path /content/?", prop, false},
+		{"-path /[a-c]ontent/*", node, true},
+		{"-path /[!a-c]ontent/*", node, false},
+		{"-path /cöntent/*", node, false},
+		{"-path /cöntent/*", utf8, true},
+		{"-path /c?ntent/*", utf8, true},
+		{"-path /c[ö]ntent/x", utf8, true},
+		{"-type node", node, true},
+		{"-type node", prop, false},
+		{"-type property", prop, true},
+		{"-ptype binary", prop, true},
+		{"-ptype string", prop, false},
+		{"-depth 2", node, true},
+		{"-depth +2", prop, true},
+		{"-depth -2", node, false},
+		{"-size 2k", prop, true},
+		{"-size +1k", prop, true},
+		{"-size -2048", prop, false},
+		{"-children +2", node, true},
+		{"-children +2", prop, false},
+		{"-has-property jcr:primaryType", root, true},
+		{"-has-property jcr:primaryType", node, false},
+		{"-type node -name site", node, true},
+		{"-type node -a -name site", prop, false},
+		{"-type node -o -name data", prop, true},
+		{"! -type node", prop, true},
+		{"-not -type node", node, false},
+		{"( -name data -o -name site ) -depth 3", prop, true},
+		{"( -name data -o -name site ) -depth 3", node, false},
+		{"-name data -o -name site -depth 3", node, false},
+	}
+
+	for _, tt := range tests {
+		predicate, err := Parse(strings.Fields(tt.expression))
+		if err != nil {
+			t.Errorf("expression %q: unexpected error: %v\n", tt.expression, err)
+			continue
+		}
+		if match := predicate(tt.item); match != tt.match {
+			t.Errorf("expression %q: expected %v, got %v\n", tt.expression, tt.match, match)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		"-name",
+		"-name [",
+		"-type file",
+		"-depth x",
+		"-size 1T",
+		"-unknown x",
+		"( -name x",
+		"-name x )",
+		"!",
+		"-name x -o",
+	}
+
+	for _, expression := range tests {
+		if _, err := Parse(strings.Fields(expression)); err == nil {
+			t.Errorf("expression %q: expected error, got nil\n", expression)
+		}
+	}
+}
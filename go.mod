@@ -1,6 +1,13 @@
 module github.com/francescomari/nu
 
+go 1.27.1
+
 require (
 	github.com/spf13/cobra v0.0.3
+	gopkg.in/yaml.v2 v2.4.0
+)
+
+require (
 	github.com/spf13/pflag v1.0.3 // indirect
+	gopkg.in/check.v1 v0.0.0-20161208181325-20d25e280405 // indirect
 )
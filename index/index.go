@@ -0,0 +1,334 @@
+package index
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/paths"
+)
+
+var (
+	// ErrInvalidIndex is returned when an index can't be read.
+	ErrInvalidIndex = errors.New("invalid index")
+	// ErrStaleIndex is returned when an index was not built for an export.
+	ErrStaleIndex = errors.New("index doesn't match the export")
+)
+
+const (
+	magic = "nu-index"
+	// version is the version of the index format. Version 2 records the size
+	// and the modification time of the export, and escapes the paths. Version
+	// 3 addresses same-name siblings by their index.
+	version = 3
+)
+
+// Entry is the position of a node in an export.
+type Entry struct {
+	// Path is the fully qualified path of the node. Same-name siblings after
+	// the first are addressed by their index, like in `/a/b[2]`.
+	Path string
+	// Offset is the offset in bytes of the `r` or `c` command of the node.
+	Offset int64
+	// Length is the length in bytes of the subtree of the node, from the `r`
+	// or `c` command of the node to the `^` command closing it.
+	Length int64
+	// Descendants is the number of descendants of the node.
+	Descendants int
+}
+
+// Index maps the path of every node in an export to its position. Entries are
+// sorted in the order their nodes appear in the export.
+type Index struct {
+	Entries []Entry
+	// Size is the size in bytes of the indexed export.
+	Size int64
+	// ModTime is the modification time of the indexed export, or the zero
+	// time if unknown.
+	ModTime time.Time
+	byPath  map[string]int
+}
+
+// Build reads an export in the text format from a io.Reader and builds an
+// index for it.
+func Build(r io.Reader) (*Index, error) {
+	var (
+		index    = Index{byPath: make(map[string]int)}
+		buffered = bufio.NewReader(r)
+		// stack contains the positions in Entries of the open nodes, and -1
+		// for every open property.
+		stack    []int
+		path     paths.Path
+		siblings paths.Siblings
+		offset   int64
+		line     int
+	)
+
+	for {
+		content, length, err := readLine(buffered)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line++
+
+		command, argument := splitCommand(content)
+
+		switch command {
+		case 'r':
+			if len(stack) > 0 {
+				return nil, fmt.Errorf("error at line %v: %v", line, parser.ErrInvalidInput)
+			}
+			siblings.Enter("")
+			stack = append(stack, index.add(Entry{Path: "/", Offset: offset}))
+		case 'c':
+			if len(stack) == 0 || stack[len(stack)-1] < 0 {
+				return nil, fmt.Errorf("error at line %v: %v", line, parser.ErrInvalidInput)
+			}
+			path = append(path, paths.Segment{Name: argument, Index: siblings.Enter(argument)})
+			stack = append(stack, index.add(Entry{Path: path.String(), Offset: offset}))
+		case 'p':
+			if len(stack) == 0 || stack[len(stack)-1] < 0 {
+				return nil, fmt.Errorf("error at line %v: %v", line, parser.ErrInvalidInput)
+			}
+			siblings.EnterProperty()
+			stack = append(stack, -1)
+		case '^':
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("error at line %v: %v", line, parser.ErrInvalidInput)
+			}
+			if i := stack[len(stack)-1]; i >= 0 {
+				index.Entries[i].Length = offset + length - index.Entries[i].Offset
+				index.Entries[i].Descendants = len(index.Entries) - i - 1
+				if len(path) > 0 {
+					path = path[:len(path)-1]
+				}
+			}
+			siblings.Leave()
+			stack = stack[:len(stack)-1]
+		case 'v', 'x', 0:
+			// Values and empty lines don't affect the structure of the tree.
+		default:
+			return nil, fmt.Errorf("error at line %v: %v", line, parser.ErrInvalidInput)
+		}
+
+		offset += length
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("error at line %v: %v", line, io.ErrUnexpectedEOF)
+	}
+
+	index.Size = offset
+
+	return &index, nil
+}
+
+func (ix *Index) add(e Entry) int {
+	ix.Entries = append(ix.Entries, e)
+	ix.byPath[e.Path] = len(ix.Entries) - 1
+	return len(ix.Entries) - 1
+}
+
+// readLine reads a line and returns its length in bytes. The content of the
+// line is returned only if the line doesn't contain a value, since values can
+// be arbitrarily big and are not relevant for the index.
+func readLine(r *bufio.Reader) ([]byte, int64, error) {
+	var (
+		content []byte
+		length  int64
+		keep    = true
+	)
+
+	for {
+		chunk, err := r.ReadSlice('\n')
+
+		if length == 0 && len(chunk) > 0 {
+			if command, _ := splitCommand(chunk); command == 'v' || command == 'x' {
+				keep = false
+			}
+		}
+
+		length += int64(len(chunk))
+
+		if keep {
+			content = append(content, chunk...)
+		}
+
+		switch err {
+		case nil:
+			return content, length, nil
+		case bufio.ErrBufferFull:
+			continue
+		case io.EOF:
+			if length == 0 {
+				return nil, 0, io.EOF
+			}
+			return content, length, nil
+		default:
+			return nil, 0, err
+		}
+	}
+}
+
+// splitCommand returns the command of a line and its argument. The argument is
+// computed like the parser does for the name of a `c` command.
+func splitCommand(line []byte) (byte, string) {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	line = bytes.TrimLeftFunc(line, unicode.IsSpace)
+	if len(line) == 0 {
+		return 0, ""
+	}
+	return line[0], string(bytes.TrimLeftFunc(line[1:], unicode.IsSpace))
+}
+
+// Lookup returns the entry for the node at the specified path.
+func (ix *Index) Lookup(path string) (Entry, bool) {
+	i, ok := ix.byPath[path]
+	if !ok {
+		return Entry{}, false
+	}
+	return ix.Entries[i], true
+}
+
+// Write writes the index into a io.Writer.
+func (ix *Index) Write(w io.Writer) error {
+	buffered := bufio.NewWriter(w)
+
+	var modTime int64
+	if !ix.ModTime.IsZero() {
+		modTime = ix.ModTime.UnixNano()
+	}
+
+	fmt.Fprintf(buffered, "%s %d %d %d\n", magic, version, ix.Size, modTime)
+
+	for _, e := range ix.Entries {
+		fmt.Fprintf(buffered, "%d %d %d %s\n", e.Offset, e.Length, e.Descendants, e.Path)
+	}
+
+	return buffered.Flush()
+}
+
+// Read reads an index previously written by Write from a io.Reader.
+func Read(r io.Reader) (*Index, error) {
+	var (
+		index   = Index{byPath: make(map[string]int)}
+		scanner = bufio.NewScanner(r)
+	)
+
+	scanner.Buffer(nil, 1024*1024)
+
+	if !scanner.Scan() {
+		return nil, ErrInvalidIndex
+	}
+	if err := index.readHeader(scanner.Text()); err != nil {
+		return nil, err
+	}
+
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), " ", 4)
+		if len(fields) != 4 {
+			return nil, ErrInvalidIndex
+		}
+
+		offset, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, ErrInvalidIndex
+		}
+		length, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, ErrInvalidIndex
+		}
+		descendants, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, ErrInvalidIndex
+		}
+
+		index.add(Entry{
+			Path:        fields[3],
+			Offset:      offset,
+			Length:      length,
+			Descendants: descendants,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &index, nil
+}
+
+// readHeader reads the magic string, the version, the size, and the
+// modification time from the first line of an index.
+func (ix *Index) readHeader(line string) error {
+	fields := strings.Split(line, " ")
+	if len(fields) < 2 || fields[0] != magic {
+		return ErrInvalidIndex
+	}
+
+	if v, err := strconv.Atoi(fields[1]); err != nil || v != version {
+		return fmt.Errorf("%v: unsupported version %v, rebuild the index", ErrInvalidIndex, fields[1])
+	}
+
+	if len(fields) != 4 {
+		return ErrInvalidIndex
+	}
+
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return ErrInvalidIndex
+	}
+	modTime, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return ErrInvalidIndex
+	}
+
+	ix.Size = size
+	if modTime != 0 {
+		ix.ModTime = time.Unix(0, modTime)
+	}
+
+	return nil
+}
+
+// Check verifies that the index was built for an export, by comparing the size
+// and the modification time of the export with the ones recorded in the index.
+// The modification time is compared only if the index recorded it.
+func (ix *Index) Check(export os.FileInfo) error {
+	if export.Size() != ix.Size {
+		return fmt.Errorf("%v: the export has %v bytes, but the index was built for %v bytes", ErrStaleIndex, export.Size(), ix.Size)
+	}
+	if !ix.ModTime.IsZero() && !export.ModTime().Equal(ix.ModTime) {
+		return fmt.Errorf("%v: the export was modified at %v, but the index was built for %v", ErrStaleIndex, export.ModTime(), ix.ModTime)
+	}
+	return nil
+}
+
+// Children returns the entries of the children of the node described by an
+// entry.
+func (ix *Index) Children(e Entry) []Entry {
+	i, ok := ix.byPath[e.Path]
+	if !ok {
+		return nil
+	}
+
+	var children []Entry
+
+	for j := i + 1; j <= i+ix.Entries[i].Descendants; j += ix.Entries[j].Descendants + 1 {
+		children = append(children, ix.Entries[j])
+	}
+
+	return children
+}
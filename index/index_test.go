@@ -0,0 +1,238 @@
+package index
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const export = `r
+p string a
+v a
+^
+c b
+p binary c
+x cafe
+^
+c d
+^
+^
+c e e
+^
+^
+`
+
+func TestBuild(t *testing.T) {
+	index, err := Build(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("build: %v\n", err)
+	}
+
+	expected := []Entry{
+		{Path: "/", Offset: 0, Length: int64(len(export)), Descendants: 3},
+		{Path: "/b", Offset: 19, Length: 32, Descendants: 1},
+		{Path: "/b/d", Offset: 43, Length: 6, Descendants: 0},
+		{Path: "/e e", Offset: 51, Length: 8, Descendants: 0},
+	}
+
+	if len(index.Entries) != len(expected) {
+		t.Fatalf("expected %v entries, got %v\n", len(expected), len(index.Entries))
+	}
+	for i, e := range expected {
+		if e != index.Entries[i] {
+			t.Errorf("expected %v, got %v\n", e, index.Entries[i])
+		}
+	}
+}
+
+func TestBuildInvalid(t *testing.T) {
+	tests := []string{
+		"c a\n",
+		"r\nr\n",
+		"r\np string a\nc b\n",
+		"r\n^\n^\n",
+		"r\nc a\n^\n",
+		"r\nz\n^\n",
+	}
+
+	for _, tt := range tests {
+		if _, err := Build(strings.NewReader(tt)); err == nil {
+			t.Errorf("building '%v': expected error\n", tt)
+		}
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	index, err := Build(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("build: %v\n", err)
+	}
+
+	var w strings.Builder
+
+	if err := index.Write(&w); err != nil {
+		t.Fatalf("write: %v\n", err)
+	}
+
+	read, err := Read(strings.NewReader(w.String()))
+	if err != nil {
+		t.Fatalf("read: %v\n", err)
+	}
+
+	if len(read.Entries) != len(index.Entries) {
+		t.Fatalf("expected %v entries, got %v\n", len(index.Entries), len(read.Entries))
+	}
+	for i, e := range index.Entries {
+		if e != read.Entries[i] {
+			t.Errorf("expected %v, got %v\n", e, read.Entries[i])
+		}
+	}
+
+	if read.Size != int64(len(export)) {
+		t.Errorf("expected size %v, got %v\n", len(export), read.Size)
+	}
+	if !read.ModTime.IsZero() {
+		t.Errorf("expected zero modification time, got %v\n", read.ModTime)
+	}
+
+	if e, ok := read.Lookup("/e e"); !ok || e != index.Entries[3] {
+		t.Errorf("lookup: expected %v, got %v\n", index.Entries[3], e)
+	}
+	if _, ok := read.Lookup("/x"); ok {
+		t.Errorf("lookup: unexpected entry for /x\n")
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"invalid\n",
+		"nu-index 1\n0 59 3 /\n",
+		"nu-index 2 59 0\n0 59 3 /\n",
+		"nu-index 3\n",
+		"nu-index 3 x 0\n",
+		"nu-index 3 59 0\n0 0 0\n",
+		"nu-index 3 59 0\na 0 0 /\n",
+	}
+
+	for _, tt := range tests {
+		if _, err := Read(strings.NewReader(tt)); err == nil {
+			t.Errorf("reading '%v': expected error\n", tt)
+		}
+	}
+}
+
+func TestChildren(t *testing.T) {
+	index, err := Build(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("build: %v\n", err)
+	}
+
+	tests := []struct {
+		path     string
+		children []string
+	}{
+		{"/", []string{"/b", "/e e"}},
+		{"/b", []string{"/b/d"}},
+		{"/b/d", nil},
+	}
+
+	for _, tt := range tests {
+		e, ok := index.Lookup(tt.path)
+		if !ok {
+			t.Fatalf("lookup %v: not found\n", tt.path)
+		}
+		var children []string
+		for _, child := range index.Children(e) {
+			children = append(children, child.Path)
+		}
+		if strings.Join(children, ",") != strings.Join(tt.children, ",") {
+			t.Errorf("path %v: expected %v, got %v\n", tt.path, tt.children, children)
+		}
+	}
+}
+
+type fileInfo struct {
+	os.FileInfo
+	size    int64
+	modTime time.Time
+}
+
+func (fi fileInfo) Size() int64        { return fi.size }
+func (fi fileInfo) ModTime() time.Time { return fi.modTime }
+
+func TestCheck(t *testing.T) {
+	index, err := Build(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("build: %v\n", err)
+	}
+
+	var (
+		size    = int64(len(export))
+		modTime = time.Unix(1500000000, 123456789)
+	)
+
+	if err := index.Check(fileInfo{size: size, modTime: modTime}); err != nil {
+		t.Errorf("unexpected error without modification time: %v\n", err)
+	}
+	if err := index.Check(fileInfo{size: size - 1, modTime: modTime}); err == nil {
+		t.Errorf("expected error for a different size\n")
+	}
+
+	index.ModTime = modTime
+
+	var w strings.Builder
+
+	if err := index.Write(&w); err != nil {
+		t.Fatalf("write: %v\n", err)
+	}
+
+	read, err := Read(strings.NewReader(w.String()))
+	if err != nil {
+		t.Fatalf("read: %v\n", err)
+	}
+
+	if err := read.Check(fileInfo{size: size, modTime: modTime}); err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+	if err := read.Check(fileInfo{size: size, modTime: modTime.Add(time.Second)}); err == nil {
+		t.Errorf("expected error for a different modification time\n")
+	}
+}
+
+func TestBuildSameNameSiblings(t *testing.T) {
+	index, err := Build(strings.NewReader("r\nc a\nc b\n^\nc b\n^\n^\nc b\np String x\nv y\n^\n^\n^\n"))
+	if err != nil {
+		t.Fatalf("build: %v\n", err)
+	}
+
+	expected := []Entry{
+		{Path: "/", Offset: 0, Length: 45, Descendants: 4},
+		{Path: "/a", Offset: 2, Length: 18, Descendants: 2},
+		{Path: "/a/b", Offset: 6, Length: 6, Descendants: 0},
+		{Path: "/a/b[2]", Offset: 12, Length: 6, Descendants: 0},
+		{Path: "/b", Offset: 20, Length: 23, Descendants: 0},
+	}
+
+	if len(index.Entries) != len(expected) {
+		t.Fatalf("expected %v entries, got %v\n", len(expected), len(index.Entries))
+	}
+	for i, e := range expected {
+		if e != index.Entries[i] {
+			t.Errorf("expected %v, got %v\n", e, index.Entries[i])
+		}
+	}
+
+	if e, ok := index.Lookup("/a/b[2]"); !ok || e != expected[3] {
+		t.Errorf("lookup: expected %v, got %v\n", expected[3], e)
+	}
+
+	var children []string
+	for _, child := range index.Children(expected[1]) {
+		children = append(children, child.Path)
+	}
+	if strings.Join(children, ",") != "/a/b,/a/b[2]" {
+		t.Errorf("children: expected [/a/b /a/b[2]], got %v\n", children)
+	}
+}
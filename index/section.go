@@ -0,0 +1,66 @@
+package index
+
+import (
+	"io"
+
+	"github.com/francescomari/nu/parser"
+)
+
+// Subtree reads the subtree of the node described by an entry from an export,
+// and emits a stream of commands where the node is the new root. The result is
+// equivalent to filter.Subtree, but only the subtree is read from the export.
+func Subtree(export io.ReaderAt, e Entry) <-chan parser.Cmd {
+	return subtree(io.NewSectionReader(export, e.Offset, e.Length))
+}
+
+// subtree parses a subtree from a io.Reader, and replaces the command opening
+// the root of the subtree with a `r` command.
+func subtree(r io.Reader) <-chan parser.Cmd {
+	commands := parser.Parse(r)
+
+	ch := make(chan parser.Cmd)
+	go func() {
+		defer close(ch)
+
+		first := true
+
+		for command := range commands {
+			if _, ok := command.(parser.C); ok && first {
+				command = parser.R{}
+			}
+			first = false
+			ch <- command
+		}
+	}()
+	return ch
+}
+
+// Prune reads an export of the specified size, excluding the subtree of the
+// node described by an entry, and emits a stream of commands. The result is
+// equivalent to filter.Prune, but the subtree is never read from the export.
+func Prune(export io.ReaderAt, size int64, e Entry) <-chan parser.Cmd {
+	return parser.Parse(io.MultiReader(
+		io.NewSectionReader(export, 0, e.Offset),
+		io.NewSectionReader(export, e.Offset+e.Length, size-e.Offset-e.Length),
+	))
+}
+
+// Node reads the node described by an entry from an export, skipping the
+// subtrees of its children, and emits a stream of commands where the node is
+// the new root. The stream contains the properties of the node, but none of its
+// children.
+func (ix *Index) Node(export io.ReaderAt, e Entry) <-chan parser.Cmd {
+	var (
+		sections []io.Reader
+		offset   = e.Offset
+	)
+
+	for _, child := range ix.Children(e) {
+		sections = append(sections, io.NewSectionReader(export, offset, child.Offset-offset))
+		offset = child.Offset + child.Length
+	}
+
+	sections = append(sections, io.NewSectionReader(export, offset, e.Offset+e.Length-offset))
+
+	return subtree(io.MultiReader(sections...))
+}
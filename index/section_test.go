@@ -0,0 +1,107 @@
+package index
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/filter"
+	"github.com/francescomari/nu/parser"
+)
+
+func collect(ch <-chan parser.Cmd) []parser.Cmd {
+	var cmds []parser.Cmd
+	for cmd := range ch {
+		cmds = append(cmds, cmd)
+	}
+	return cmds
+}
+
+func assertCommandsEqual(t *testing.T, expected, got []parser.Cmd) {
+	t.Helper()
+	if len(expected) != len(got) {
+		t.Fatalf("expected %v, got %v\n", expected, got)
+	}
+	for i := range expected {
+		if expected[i] != got[i] {
+			t.Fatalf("expected %v, got %v\n", expected, got)
+		}
+	}
+}
+
+func TestSubtree(t *testing.T) {
+	index, err := Build(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("build: %v\n", err)
+	}
+
+	for _, e := range index.Entries {
+		expected, err := filter.Subtree(e.Path, parser.Parse(strings.NewReader(export)))
+		if err != nil {
+			t.Fatalf("subtree: %v\n", err)
+		}
+		assertCommandsEqual(t, collect(expected), collect(Subtree(strings.NewReader(export), e)))
+	}
+}
+
+func TestPrune(t *testing.T) {
+	index, err := Build(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("build: %v\n", err)
+	}
+
+	for _, e := range index.Entries {
+		expected, err := filter.Prune(e.Path, parser.Parse(strings.NewReader(export)))
+		if err != nil {
+			t.Fatalf("prune: %v\n", err)
+		}
+		assertCommandsEqual(t, collect(expected), collect(Prune(strings.NewReader(export), int64(len(export)), e)))
+	}
+}
+
+func TestNode(t *testing.T) {
+	index, err := Build(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("build: %v\n", err)
+	}
+
+	tests := []struct {
+		path     string
+		expected []parser.Cmd
+	}{
+		{
+			"/",
+			[]parser.Cmd{
+				parser.R{},
+				parser.P{Type: "string", Name: "a"},
+				parser.V{Data: "a"},
+				parser.Up{},
+				parser.Up{},
+			},
+		},
+		{
+			"/b",
+			[]parser.Cmd{
+				parser.R{},
+				parser.P{Type: "binary", Name: "c"},
+				parser.X{Data: "cafe"},
+				parser.Up{},
+				parser.Up{},
+			},
+		},
+		{
+			"/e e",
+			[]parser.Cmd{
+				parser.R{},
+				parser.Up{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		e, ok := index.Lookup(tt.path)
+		if !ok {
+			t.Fatalf("lookup %v: not found\n", tt.path)
+		}
+		assertCommandsEqual(t, tt.expected, collect(index.Node(strings.NewReader(export), e)))
+	}
+}
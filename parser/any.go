@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"bufio"
+	"io"
+	"unicode"
+)
+
+type format int
+
+const (
+	formatText format = iota
+	formatBinary
+	formatSysView
+	formatJSON
+)
+
+// ParseAny detects the format of the data in the specified io.Reader and parses
+// it with the matching parser. Data starting with BinaryMagic is parsed with
+// ParseBinary. Otherwise, leading whitespace is ignored, data starting with `<`
+// is parsed with ParseSysView, data starting with `{` is parsed with
+// ParseJSON, and any other data is parsed with Parse.
+func ParseAny(reader io.Reader) <-chan Cmd {
+	buffered := bufio.NewReader(reader)
+
+	switch detect(buffered) {
+	case formatBinary:
+		return ParseBinary(buffered)
+	case formatSysView:
+		return ParseSysView(buffered)
+	case formatJSON:
+		return ParseJSON(buffered)
+	default:
+		return Parse(buffered)
+	}
+}
+
+// ParseAnyLines detects the format of the data in the specified io.Reader like
+// ParseAny. Data in the text format is parsed with ParseLines. Data in any
+// other format is parsed like in ParseAny, and its commands are emitted with a
+// Line of zero.
+func ParseAnyLines(reader io.Reader) <-chan LineCmd {
+	buffered := bufio.NewReader(reader)
+
+	if detect(buffered) == formatText {
+		return ParseLines(buffered)
+	}
+
+	ch := make(chan LineCmd)
+	go func() {
+		defer close(ch)
+		for cmd := range ParseAny(buffered) {
+			ch <- LineCmd{Cmd: cmd}
+		}
+	}()
+	return ch
+}
+
+func detect(buffered *bufio.Reader) format {
+	if magic, err := buffered.Peek(len(BinaryMagic)); err == nil && string(magic) == BinaryMagic {
+		return formatBinary
+	}
+
+	for n := 1; ; n++ {
+		peeked, err := buffered.Peek(n)
+		if err != nil {
+			return formatText
+		}
+
+		c := rune(peeked[n-1])
+
+		switch {
+		case unicode.IsSpace(c):
+			continue
+		case c == '<':
+			return formatSysView
+		case c == '{':
+			return formatJSON
+		default:
+			return formatText
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAny(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []Cmd
+	}{
+		{
+			"\n r\n ^\n",
+			[]Cmd{R{}, Up{}},
+		},
+		{
+			` {"nodes": [{"name": "a"}]}`,
+			[]Cmd{R{}, C{"a"}, Up{}, Up{}},
+		},
+		{
+			` <sv:node xmlns:sv="http://www.jcp.org/jcr/sv/1.0" sv:name="jcr:root"><sv:node sv:name="a"/></sv:node>`,
+			[]Cmd{R{}, C{"a"}, Up{}, Up{}},
+		},
+		{
+			BinaryMagic + "rc\x00\x01a^^",
+			[]Cmd{R{}, C{"a"}, Up{}, Up{}},
+		},
+		{
+			"",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		var all []Cmd
+		for c := range ParseAny(strings.NewReader(tt.input)) {
+			all = append(all, c)
+		}
+		if len(all) != len(tt.expected) {
+			t.Errorf("parsing '%v': expected %d commands, got %d: %v\n", tt.input, len(tt.expected), len(all), all)
+			continue
+		}
+		for i, a := range tt.expected {
+			if a != all[i] {
+				t.Errorf("parsing '%v': expected %v, got %v\n", tt.input, a, all[i])
+			}
+		}
+	}
+}
+
+func TestParseAnyLines(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []LineCmd
+	}{
+		{
+			"\n r\n\n c a\n v x\\ny\n ^\n^",
+			[]LineCmd{{R{}, 2}, {C{"a"}, 4}, {V{"x\ny"}, 5}, {Up{}, 6}, {Up{}, 7}},
+		},
+		{
+			` {"nodes": [{"name": "a"}]}`,
+			[]LineCmd{{R{}, 0}, {C{"a"}, 0}, {Up{}, 0}, {Up{}, 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		var all []LineCmd
+		for c := range ParseAnyLines(strings.NewReader(tt.input)) {
+			all = append(all, c)
+		}
+		if len(all) != len(tt.expected) {
+			t.Errorf("parsing '%v': expected %d commands, got %d: %v\n", tt.input, len(tt.expected), len(all), all)
+			continue
+		}
+		for i, a := range tt.expected {
+			if a != all[i] {
+				t.Errorf("parsing '%v': expected %v, got %v\n", tt.input, a, all[i])
+			}
+		}
+	}
+}
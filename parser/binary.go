@@ -0,0 +1,179 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	// BinaryMagic is the sequence of bytes at the beginning of every export in
+	// the binary format.
+	BinaryMagic = "NU\x00\x01"
+	// BinaryMaxStrings is the maximum size of the string table of an export in
+	// the binary format. When the table is full, new names and types are
+	// written inline and are not added to the table.
+	BinaryMaxStrings = 1 << 16
+)
+
+// Opcodes of the commands in the binary format. Every command is encoded as its
+// opcode, followed by its arguments. Names and types are encoded as references
+// to the string table. A reference is an unsigned varint, where zero means that
+// the string follows inline, and any other number is the 1-based index of the
+// string in the table. Inline strings, and the data of V commands, are encoded
+// as an unsigned varint length followed by the bytes of the string. The payload
+// of X commands is stored decoded when its hexadecimal representation is
+// canonical, and as a string otherwise.
+const (
+	BinaryOpR       = 'r'
+	BinaryOpUp      = '^'
+	BinaryOpC       = 'c'
+	BinaryOpP       = 'p'
+	BinaryOpV       = 'v'
+	BinaryOpX       = 'x'
+	BinaryOpXString = 'X'
+)
+
+// ParseBinary parses an export in the binary format from the specified
+// io.Reader and emits a stream of commands. The Line of an emitted Err is the
+// 1-based position in the stream of the command that failed to parse.
+func ParseBinary(reader io.Reader) <-chan Cmd {
+	ch := make(chan Cmd)
+	go parseBinary(reader, ch)
+	return ch
+}
+
+func parseBinary(reader io.Reader, ch chan<- Cmd) {
+	defer close(ch)
+
+	p := binaryParser{r: bufio.NewReader(reader)}
+
+	magic := make([]byte, len(BinaryMagic))
+	if _, err := io.ReadFull(p.r, magic); err != nil || string(magic) != BinaryMagic {
+		ch <- Err{Err: ErrInvalidInput, Line: 1}
+		return
+	}
+
+	for line := 1; ; line++ {
+		cmd, err := p.next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			ch <- Err{Err: err, Line: line}
+			return
+		}
+		ch <- cmd
+	}
+}
+
+type binaryParser struct {
+	r       *bufio.Reader
+	strings []string
+}
+
+func (p *binaryParser) next() (Cmd, error) {
+	op, err := p.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case BinaryOpR:
+		return R{}, nil
+	case BinaryOpUp:
+		return Up{}, nil
+	case BinaryOpC:
+		name, err := p.readRef()
+		if err != nil {
+			return nil, err
+		}
+		return C{name}, nil
+	case BinaryOpP:
+		typ, err := p.readRef()
+		if err != nil {
+			return nil, err
+		}
+		name, err := p.readRef()
+		if err != nil {
+			return nil, err
+		}
+		return P{typ, name}, nil
+	case BinaryOpV:
+		data, err := p.readString()
+		if err != nil {
+			return nil, err
+		}
+		return V{data}, nil
+	case BinaryOpX:
+		data, err := p.readString()
+		if err != nil {
+			return nil, err
+		}
+		return X{hex.EncodeToString([]byte(data))}, nil
+	case BinaryOpXString:
+		data, err := p.readString()
+		if err != nil {
+			return nil, err
+		}
+		return X{data}, nil
+	default:
+		return nil, fmt.Errorf("%v: unknown opcode %v", ErrInvalidInput, op)
+	}
+}
+
+func (p *binaryParser) readRef() (string, error) {
+	ref, err := binary.ReadUvarint(p.r)
+	if err != nil {
+		return "", unexpectedEOF(err)
+	}
+
+	if ref > 0 {
+		if ref > uint64(len(p.strings)) {
+			return "", fmt.Errorf("%v: invalid string reference %v", ErrInvalidInput, ref)
+		}
+		return p.strings[ref-1], nil
+	}
+
+	s, err := p.readString()
+	if err != nil {
+		return "", err
+	}
+
+	if len(p.strings) < BinaryMaxStrings {
+		p.strings = append(p.strings, s)
+	}
+
+	return s, nil
+}
+
+func (p *binaryParser) readString() (string, error) {
+	n, err := binary.ReadUvarint(p.r)
+	if err != nil {
+		return "", unexpectedEOF(err)
+	}
+
+	if n > math.MaxInt64 {
+		return "", fmt.Errorf("%v: invalid string length %v", ErrInvalidInput, n)
+	}
+
+	// The length comes from the input, so the buffer only grows as the data is
+	// actually read.
+	var data bytes.Buffer
+	if _, err := io.CopyN(&data, p.r, int64(n)); err != nil {
+		return "", unexpectedEOF(err)
+	}
+
+	return data.String(), nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func parseBinaryAll(s string) []Cmd {
+	var cmds []Cmd
+	for c := range ParseBinary(strings.NewReader(s)) {
+		cmds = append(cmds, c)
+	}
+	return cmds
+}
+
+func TestParseBinary(t *testing.T) {
+	var data string
+
+	data += BinaryMagic
+	data += "r"
+	data += "c\x00\x01a"
+	data += "p\x00\x06string\x01"
+	data += "v\x03a\nb"
+	data += "^"
+	data += "p\x02\x00\x01b"
+	data += "x\x02\xca\xfe"
+	data += "X\x02AB"
+	data += "^"
+	data += "^"
+	data += "c\x01"
+	data += "^"
+	data += "^"
+
+	expected := []Cmd{
+		R{},
+		C{"a"},
+		P{"string", "a"},
+		V{"a\nb"},
+		Up{},
+		P{"string", "b"},
+		X{"cafe"},
+		X{"AB"},
+		Up{},
+		Up{},
+		C{"a"},
+		Up{},
+		Up{},
+	}
+
+	all := parseBinaryAll(data)
+	if len(all) != len(expected) {
+		t.Fatalf("expected %d commands, got %d: %v\n", len(expected), len(all), all)
+	}
+	for i, a := range expected {
+		if a != all[i] {
+			t.Errorf("expected %v, got %v\n", a, all[i])
+		}
+	}
+}
+
+func TestParseBinaryInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"r",
+		BinaryMagic + "?",
+		BinaryMagic + "c\x01",
+		BinaryMagic + "c\x00\x05a",
+		BinaryMagic + "v",
+		BinaryMagic + "p\x00\x01a",
+		BinaryMagic + "v\x80\x80\x80\x80\x80\x80\x80\x80\x40abc",
+		BinaryMagic + "v\xff\xff\xff\xff\xff\xff\xff\xff\xff\x01abc",
+	}
+
+	for _, tt := range tests {
+		all := parseBinaryAll(tt)
+		if len(all) == 0 {
+			t.Errorf("parsing %q: expected error, got no commands\n", tt)
+			continue
+		}
+		if _, ok := all[len(all)-1].(Err); !ok {
+			t.Errorf("parsing %q: expected error, got %v\n", tt, all[len(all)-1])
+		}
+	}
+}
@@ -65,3 +65,10 @@ type Err struct {
 
 func (Err) cmd() {
 }
+
+// LineCmd is a command along with the line of the export where it is
+// defined. A Line of zero means that the line is unknown.
+type LineCmd struct {
+	Cmd  Cmd
+	Line int
+}
@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// DirPropertiesFile is the name of the optional file, inside a directory,
+	// that contains the properties of the node corresponding to that
+	// directory. The file uses the export format, but can only contain `p`,
+	// `v`, `x`, and `^` commands.
+	DirPropertiesFile = ".properties"
+	// DirBinaryProperty is the name of the property holding the content of a
+	// file.
+	DirBinaryProperty = "binary"
+	// DirBinaryType is the type of the property holding the content of a
+	// file.
+	DirBinaryType = "binary"
+)
+
+// ParseDir converts the directory tree rooted at root into a stream of
+// commands. Directories are converted to nodes, and files are converted to
+// nodes with a single binary property containing the content of the file. The
+// properties of a directory are read from DirPropertiesFile, if present. Errors
+// are emitted as Err commands whose Line is always zero.
+func ParseDir(root string) <-chan Cmd {
+	ch := make(chan Cmd)
+	go parseDir(root, ch)
+	return ch
+}
+
+func parseDir(root string, ch chan<- Cmd) {
+	defer close(ch)
+
+	info, err := os.Stat(root)
+	if err != nil {
+		ch <- Err{Err: err}
+		return
+	}
+	if !info.IsDir() {
+		ch <- Err{Err: fmt.Errorf("%v: not a directory", root)}
+		return
+	}
+
+	ch <- R{}
+
+	if err := parseDirContent(root, ch); err != nil {
+		ch <- Err{Err: err}
+		return
+	}
+
+	ch <- Up{}
+}
+
+func parseDirContent(dir string, ch chan<- Cmd) error {
+	if err := parseDirProperties(filepath.Join(dir, DirPropertiesFile), ch); err != nil {
+		return err
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, info := range infos {
+		if info.Name() == DirPropertiesFile {
+			continue
+		}
+
+		ch <- C{info.Name()}
+
+		if info.IsDir() {
+			if err := parseDirContent(filepath.Join(dir, info.Name()), ch); err != nil {
+				return err
+			}
+		} else {
+			if err := parseDirFile(filepath.Join(dir, info.Name()), ch); err != nil {
+				return err
+			}
+		}
+
+		ch <- Up{}
+	}
+
+	return nil
+}
+
+func parseDirFile(path string, ch chan<- Cmd) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	ch <- P{DirBinaryType, DirBinaryProperty}
+	ch <- X{hex.EncodeToString(data)}
+	ch <- Up{}
+
+	return nil
+}
+
+func parseDirProperties(path string, ch chan<- Cmd) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	commands := Parse(f)
+
+	// Drain the commands in case of early return, so that the parser can
+	// terminate.
+	defer func() {
+		for range commands {
+		}
+	}()
+
+	var inProperty bool
+
+	for command := range commands {
+		switch cmd := command.(type) {
+		case P:
+			if inProperty {
+				return fmt.Errorf("%v: unexpected command %T", path, cmd)
+			}
+			inProperty = true
+			ch <- cmd
+		case V, X:
+			if !inProperty {
+				return fmt.Errorf("%v: unexpected command %T", path, cmd)
+			}
+			ch <- cmd
+		case Up:
+			if !inProperty {
+				return fmt.Errorf("%v: unexpected command %T", path, cmd)
+			}
+			inProperty = false
+			ch <- cmd
+		case Err:
+			return fmt.Errorf("%v: error at line %v: %v", path, cmd.Line, cmd.Err)
+		default:
+			return fmt.Errorf("%v: unexpected command %T", path, cmd)
+		}
+	}
+
+	if inProperty {
+		return fmt.Errorf("%v: unterminated property", path)
+	}
+
+	return nil
+}
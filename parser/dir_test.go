@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func parseDirAll(root string) []Cmd {
+	var cmds []Cmd
+	for c := range ParseDir(root) {
+		cmds = append(cmds, c)
+	}
+	return cmds
+}
+
+func TestParseDir(t *testing.T) {
+	root, err := ioutil.TempDir("", "nu")
+	if err != nil {
+		t.Fatalf("create temporary directory: %v\n", err)
+	}
+	defer os.RemoveAll(root)
+
+	files := map[string]string{
+		".properties":     "p string title\nv root\n^\n",
+		"a/.properties":   "p long size\nv 1\nv 2\n^\np binary data\nx cafe\n^\n",
+		"a/b/hello.txt":   "hello",
+		"c.txt":           "",
+		"d/.properties.x": "\x01",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("create directory: %v\n", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("write file: %v\n", err)
+		}
+	}
+
+	expected := []Cmd{
+		R{},
+		P{"string", "title"},
+		V{"root"},
+		Up{},
+		C{"a"},
+		P{"long", "size"},
+		V{"1"},
+		V{"2"},
+		Up{},
+		P{"binary", "data"},
+		X{"cafe"},
+		Up{},
+		C{"b"},
+		C{"hello.txt"},
+		P{"binary", "binary"},
+		X{"68656c6c6f"},
+		Up{},
+		Up{},
+		Up{},
+		Up{},
+		C{"c.txt"},
+		P{"binary", "binary"},
+		X{""},
+		Up{},
+		Up{},
+		C{"d"},
+		C{".properties.x"},
+		P{"binary", "binary"},
+		X{"01"},
+		Up{},
+		Up{},
+		Up{},
+		Up{},
+	}
+
+	all := parseDirAll(root)
+	if len(all) != len(expected) {
+		t.Fatalf("expected %d commands, got %d: %v\n", len(expected), len(all), all)
+	}
+	for i, a := range expected {
+		if a != all[i] {
+			t.Errorf("expected %v, got %v\n", a, all[i])
+		}
+	}
+}
+
+func TestParseDirInvalidProperties(t *testing.T) {
+	tests := []string{
+		"c child\n",
+		"v value\n",
+		"^\n",
+		"p string a\np string b\n^\n",
+		"p string a\nv value\n",
+		"invalid\n",
+	}
+
+	for _, tt := range tests {
+		root, err := ioutil.TempDir("", "nu")
+		if err != nil {
+			t.Fatalf("create temporary directory: %v\n", err)
+		}
+		defer os.RemoveAll(root)
+
+		if err := ioutil.WriteFile(filepath.Join(root, DirPropertiesFile), []byte(tt), 0644); err != nil {
+			t.Fatalf("write file: %v\n", err)
+		}
+
+		all := parseDirAll(root)
+		if _, ok := all[len(all)-1].(Err); !ok {
+			t.Errorf("properties '%v': expected error, got %v\n", tt, all[len(all)-1])
+		}
+	}
+}
+
+func TestParseDirNotADirectory(t *testing.T) {
+	f, err := ioutil.TempFile("", "nu")
+	if err != nil {
+		t.Fatalf("create temporary file: %v\n", err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	all := parseDirAll(f.Name())
+	if len(all) != 1 {
+		t.Fatalf("expected 1 command, got %v\n", len(all))
+	}
+	if _, ok := all[0].(Err); !ok {
+		t.Fatalf("expected Err, got %T\n", all[0])
+	}
+}
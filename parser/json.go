@@ -0,0 +1,244 @@
+package parser
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ParseJSON parses a JSON document, as produced by serializer.SerializeJSON,
+// from the specified io.Reader and emits a stream of commands. The document is
+// parsed while it is read, so the `name` of a node must be its first field, and
+// the `name` and `type` of a property must precede its `values`. Errors are
+// emitted as Err commands whose Line is always zero, and whose message
+// contains the offset in the input where the error was detected.
+func ParseJSON(reader io.Reader) <-chan Cmd {
+	ch := make(chan Cmd)
+	go parseJSON(reader, ch)
+	return ch
+}
+
+func parseJSON(reader io.Reader, ch chan<- Cmd) {
+	defer close(ch)
+
+	p := jsonParser{
+		decoder: json.NewDecoder(reader),
+		ch:      ch,
+	}
+
+	if err := p.parseNode(true); err != nil {
+		ch <- Err{Err: fmt.Errorf("offset %v: %v", p.decoder.InputOffset(), err)}
+	}
+}
+
+type jsonParser struct {
+	decoder *json.Decoder
+	ch      chan<- Cmd
+}
+
+func (p *jsonParser) parseNode(root bool) error {
+	if err := p.expectDelim('{'); err != nil {
+		return err
+	}
+
+	named := false
+
+	if root {
+		p.ch <- R{}
+	}
+
+	for p.decoder.More() {
+		key, err := p.readString()
+		if err != nil {
+			return err
+		}
+
+		if !root && !named && key != "name" {
+			return fmt.Errorf("%v: field name must precede field %v", ErrInvalidInput, key)
+		}
+
+		switch key {
+		case "name":
+			if named {
+				return fmt.Errorf("%v: duplicate field name", ErrInvalidInput)
+			}
+			name, err := p.readString()
+			if err != nil {
+				return err
+			}
+			if !root {
+				p.ch <- C{name}
+			}
+			named = true
+		case "properties":
+			if err := p.parseArray(p.parseProperty); err != nil {
+				return err
+			}
+		case "nodes":
+			if err := p.parseArray(func() error { return p.parseNode(false) }); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%v: unknown node field %v", ErrInvalidInput, key)
+		}
+	}
+
+	if err := p.expectDelim('}'); err != nil {
+		return err
+	}
+
+	if !root && !named {
+		return fmt.Errorf("%v: missing node name", ErrInvalidInput)
+	}
+
+	p.ch <- Up{}
+
+	return nil
+}
+
+func (p *jsonParser) parseProperty() error {
+	if err := p.expectDelim('{'); err != nil {
+		return err
+	}
+
+	var (
+		name, typ string
+		emitted   bool
+	)
+
+	emit := func() error {
+		if emitted {
+			return nil
+		}
+		if name == "" || typ == "" {
+			return fmt.Errorf("%v: field name and type must precede field values", ErrInvalidInput)
+		}
+		p.ch <- P{typ, name}
+		emitted = true
+		return nil
+	}
+
+	for p.decoder.More() {
+		key, err := p.readString()
+		if err != nil {
+			return err
+		}
+
+		switch key {
+		case "name":
+			if emitted {
+				return fmt.Errorf("%v: field name must precede field values", ErrInvalidInput)
+			}
+			if name, err = p.readString(); err != nil {
+				return err
+			}
+		case "type":
+			if emitted {
+				return fmt.Errorf("%v: field type must precede field values", ErrInvalidInput)
+			}
+			if typ, err = p.readString(); err != nil {
+				return err
+			}
+		case "values":
+			if err := emit(); err != nil {
+				return err
+			}
+			if err := p.parseArray(p.parseValue); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%v: unknown property field %v", ErrInvalidInput, key)
+		}
+	}
+
+	if err := p.expectDelim('}'); err != nil {
+		return err
+	}
+
+	if err := emit(); err != nil {
+		return err
+	}
+
+	p.ch <- Up{}
+
+	return nil
+}
+
+func (p *jsonParser) parseValue() error {
+	token, err := p.decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if s, ok := token.(string); ok {
+		p.ch <- V{s}
+		return nil
+	}
+
+	if token != json.Delim('{') {
+		return fmt.Errorf("%v: unexpected token %v", ErrInvalidInput, token)
+	}
+
+	key, err := p.readString()
+	if err != nil {
+		return err
+	}
+	if key != "binary" {
+		return fmt.Errorf("%v: unknown value field %v", ErrInvalidInput, key)
+	}
+
+	encoded, err := p.readString()
+	if err != nil {
+		return err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("decoding binary value: %v", err)
+	}
+
+	if err := p.expectDelim('}'); err != nil {
+		return err
+	}
+
+	p.ch <- X{hex.EncodeToString(data)}
+
+	return nil
+}
+
+func (p *jsonParser) parseArray(parseElement func() error) error {
+	if err := p.expectDelim('['); err != nil {
+		return err
+	}
+	for p.decoder.More() {
+		if err := parseElement(); err != nil {
+			return err
+		}
+	}
+	return p.expectDelim(']')
+}
+
+func (p *jsonParser) expectDelim(delim json.Delim) error {
+	token, err := p.decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("%v: expected %v, got %v", ErrInvalidInput, delim, token)
+	}
+	return nil
+}
+
+func (p *jsonParser) readString() (string, error) {
+	token, err := p.decoder.Token()
+	if err != nil {
+		return "", err
+	}
+	s, ok := token.(string)
+	if !ok {
+		return "", fmt.Errorf("%v: expected string, got %v", ErrInvalidInput, token)
+	}
+	return s, nil
+}
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func parseJSONAll(s string) []Cmd {
+	var cmds []Cmd
+	for c := range ParseJSON(strings.NewReader(s)) {
+		cmds = append(cmds, c)
+	}
+	return cmds
+}
+
+func TestParseJSON(t *testing.T) {
+	doc := `{
+		"properties": [
+			{"name": "a", "type": "string", "values": ["b", "c\n\"d\""]}
+		],
+		"nodes": [
+			{
+				"name": "e",
+				"properties": [
+					{"name": "f", "type": "binary", "values": [{"binary": "aGVsbG8="}]},
+					{"name": "g", "type": "long", "values": []},
+					{"type": "long", "name": "h"}
+				],
+				"nodes": [{"name": "i"}]
+			},
+			{"name": "j"}
+		]
+	}`
+
+	expected := []Cmd{
+		R{},
+		P{"string", "a"},
+		V{"b"},
+		V{"c\n\"d\""},
+		Up{},
+		C{"e"},
+		P{"binary", "f"},
+		X{"68656c6c6f"},
+		Up{},
+		P{"long", "g"},
+		Up{},
+		P{"long", "h"},
+		Up{},
+		C{"i"},
+		Up{},
+		Up{},
+		C{"j"},
+		Up{},
+		Up{},
+	}
+
+	all := parseJSONAll(doc)
+	if len(all) != len(expected) {
+		t.Fatalf("expected %d commands, got %d: %v\n", len(expected), len(all), all)
+	}
+	for i, a := range expected {
+		if a != all[i] {
+			t.Errorf("expected %v, got %v\n", a, all[i])
+		}
+	}
+}
+
+func TestParseJSONInvalid(t *testing.T) {
+	tests := []string{
+		``,
+		`[]`,
+		`{"unknown": 1}`,
+		`{"nodes": [{"properties": []}]}`,
+		`{"nodes": [{}]}`,
+		`{"properties": [{"values": [], "name": "a", "type": "b"}]}`,
+		`{"properties": [{"name": "a", "type": "b", "values": [1]}]}`,
+		`{"properties": [{"name": "a", "type": "b", "values": [{"binary": "!"}]}]}`,
+		`{"properties": [{"name": "a", "type": "b", "values": [{"other": ""}]}]}`,
+		`{"nodes": [`,
+	}
+
+	for _, tt := range tests {
+		all := parseJSONAll(tt)
+		if len(all) == 0 {
+			t.Errorf("parsing '%v': expected error, got no commands\n", tt)
+			continue
+		}
+		if _, ok := all[len(all)-1].(Err); !ok {
+			t.Errorf("parsing '%v': expected error, got %v\n", tt, all[len(all)-1])
+		}
+	}
+}
@@ -11,13 +11,31 @@ import (
 // commands.
 func Parse(reader io.Reader) <-chan Cmd {
 	ch := make(chan Cmd)
-	go parse(reader, ch)
+	go func() {
+		defer close(ch)
+		parse(reader, func(cmd Cmd, line int) {
+			ch <- cmd
+		})
+	}()
 	return ch
 }
 
-func parse(reader io.Reader, ch chan<- Cmd) {
-	defer close(ch)
+// ParseLines parses an export like Parse, and emits every command along with
+// the line where it is defined.
+func ParseLines(reader io.Reader) <-chan LineCmd {
+	ch := make(chan LineCmd)
+	go func() {
+		defer close(ch)
+		parse(reader, func(cmd Cmd, line int) {
+			ch <- LineCmd{cmd, line}
+		})
+	}()
+	return ch
+}
 
+// parse parses an export and calls emit for every command and the line where
+// the command is defined.
+func parse(reader io.Reader, emit func(cmd Cmd, line int)) {
 	const (
 		stateStart = iota
 		stateEnd
@@ -50,6 +68,7 @@ func parse(reader io.Reader, ch chan<- Cmd) {
 		state    = stateStart
 		buffered = bufio.NewReader(reader)
 		line     = 1
+		start    = 1
 	)
 
 	for {
@@ -57,15 +76,19 @@ func parse(reader io.Reader, ch chan<- Cmd) {
 			break
 		}
 
+		if state == stateStart {
+			start = line
+		}
+
 		if state == stateError {
-			ch <- Err{Err: ErrInvalidInput, Line: line}
+			emit(Err{Err: ErrInvalidInput, Line: line}, line)
 			break
 		}
 
 		c, _, err := buffered.ReadRune()
 
 		if err != nil && err != io.EOF {
-			ch <- Err{Err: err, Line: line}
+			emit(Err{Err: err, Line: line}, line)
 			break
 		}
 
@@ -97,11 +120,11 @@ func parse(reader io.Reader, ch chan<- Cmd) {
 		case stateR:
 			switch {
 			case c == 0:
-				ch <- R{}
+				emit(R{}, start)
 				state = stateEnd
 			case c == '\n':
 				line++
-				ch <- R{}
+				emit(R{}, start)
 				state = stateStart
 			case unicode.IsSpace(c):
 				state = stateR
@@ -111,11 +134,11 @@ func parse(reader io.Reader, ch chan<- Cmd) {
 		case stateUp:
 			switch {
 			case c == 0:
-				ch <- Up{}
+				emit(Up{}, start)
 				state = stateEnd
 			case c == '\n':
 				line++
-				ch <- Up{}
+				emit(Up{}, start)
 				state = stateStart
 			case unicode.IsSpace(c):
 				state = stateUp
@@ -142,11 +165,11 @@ func parse(reader io.Reader, ch chan<- Cmd) {
 		case stateCName:
 			switch {
 			case c == 0:
-				ch <- C{cName}
+				emit(C{cName}, start)
 				state = stateEnd
 			case c == '\n':
 				line++
-				ch <- C{cName}
+				emit(C{cName}, start)
 				state = stateStart
 			default:
 				cName += string(c)
@@ -198,11 +221,11 @@ func parse(reader io.Reader, ch chan<- Cmd) {
 		case statePName:
 			switch {
 			case c == 0:
-				ch <- P{pType, pName}
+				emit(P{pType, pName}, start)
 				state = stateEnd
 			case c == '\n':
 				line++
-				ch <- P{pType, pName}
+				emit(P{pType, pName}, start)
 				state = stateStart
 			default:
 				pName += string(c)
@@ -211,11 +234,11 @@ func parse(reader io.Reader, ch chan<- Cmd) {
 		case stateV:
 			switch {
 			case c == 0:
-				ch <- V{}
+				emit(V{}, start)
 				state = stateEnd
 			case c == '\n':
 				line++
-				ch <- V{}
+				emit(V{}, start)
 				state = stateStart
 			case unicode.IsSpace(c):
 				state = stateVSpace
@@ -225,11 +248,11 @@ func parse(reader io.Reader, ch chan<- Cmd) {
 		case stateVSpace:
 			switch {
 			case c == 0:
-				ch <- V{}
+				emit(V{}, start)
 				state = stateEnd
 			case c == '\n':
 				line++
-				ch <- V{}
+				emit(V{}, start)
 				state = stateStart
 			case unicode.IsSpace(c):
 				state = stateVSpace
@@ -255,11 +278,11 @@ func parse(reader io.Reader, ch chan<- Cmd) {
 		case stateVData:
 			switch {
 			case c == 0:
-				ch <- V{data.String()}
+				emit(V{data.String()}, start)
 				state = stateEnd
 			case c == '\n':
 				line++
-				ch <- V{data.String()}
+				emit(V{data.String()}, start)
 				state = stateStart
 			case c == '\\':
 				state = stateVDataSlash
@@ -270,11 +293,11 @@ func parse(reader io.Reader, ch chan<- Cmd) {
 		case stateX:
 			switch {
 			case c == 0:
-				ch <- X{}
+				emit(X{}, start)
 				state = stateEnd
 			case c == '\n':
 				line++
-				ch <- X{}
+				emit(X{}, start)
 				state = stateStart
 			case unicode.IsSpace(c):
 				state = stateXSpace
@@ -284,11 +307,11 @@ func parse(reader io.Reader, ch chan<- Cmd) {
 		case stateXSpace:
 			switch {
 			case c == 0:
-				ch <- X{}
+				emit(X{}, start)
 				state = stateEnd
 			case c == '\n':
 				line++
-				ch <- X{}
+				emit(X{}, start)
 				state = stateStart
 			case unicode.IsSpace(c):
 				state = stateXSpace
@@ -300,11 +323,11 @@ func parse(reader io.Reader, ch chan<- Cmd) {
 		case stateXData:
 			switch {
 			case c == 0:
-				ch <- X{data.String()}
+				emit(X{data.String()}, start)
 				state = stateEnd
 			case c == '\n':
 				line++
-				ch <- X{data.String()}
+				emit(X{data.String()}, start)
 				state = stateStart
 			default:
 				data.WriteRune(c)
@@ -0,0 +1,175 @@
+package parser
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SysViewNamespace is the namespace of the elements and attributes of a JCR
+// System View XML document.
+const SysViewNamespace = "http://www.jcp.org/jcr/sv/1.0"
+
+// ParseSysView parses a JCR System View XML document from the specified
+// io.Reader and emits a stream of commands. The name of the outermost node is
+// ignored, since it becomes the root of the export. Property types are
+// converted with ExportType, and the Base64-encoded payload of binary values is
+// converted to the representation used by X commands.
+func ParseSysView(reader io.Reader) <-chan Cmd {
+	ch := make(chan Cmd)
+	go parseSysView(reader, ch)
+	return ch
+}
+
+func parseSysView(reader io.Reader, ch chan<- Cmd) {
+	defer close(ch)
+
+	p := sysViewParser{
+		decoder: xml.NewDecoder(reader),
+		ch:      ch,
+	}
+
+	if err := p.parse(); err != nil {
+		line, _ := p.decoder.InputPos()
+		ch <- Err{Err: err, Line: line}
+	}
+}
+
+type sysViewParser struct {
+	decoder *xml.Decoder
+	ch      chan<- Cmd
+	// depth is the number of open sv:node elements.
+	depth int
+	// property is true if a sv:property element is open.
+	property bool
+	// binary is true if the open sv:property element has a binary type.
+	binary bool
+	// value is not nil if a sv:value element is open.
+	value *strings.Builder
+}
+
+func (p *sysViewParser) parse() error {
+	for {
+		token, err := p.decoder.Token()
+		if err == io.EOF {
+			if p.depth > 0 {
+				return io.ErrUnexpectedEOF
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if err := p.onStart(t); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			if err := p.onEnd(); err != nil {
+				return err
+			}
+		case xml.CharData:
+			if p.value != nil {
+				p.value.Write(t)
+			}
+		}
+	}
+}
+
+func (p *sysViewParser) onStart(e xml.StartElement) error {
+	if !isSvName(e.Name) {
+		return fmt.Errorf("%v: unexpected element %v", ErrInvalidInput, e.Name.Local)
+	}
+
+	switch e.Name.Local {
+	case "node":
+		if p.property {
+			return fmt.Errorf("%v: unexpected element node", ErrInvalidInput)
+		}
+		name, ok := svAttr(e, "name")
+		if !ok {
+			return fmt.Errorf("%v: missing node name", ErrInvalidInput)
+		}
+		if p.depth == 0 {
+			p.ch <- R{}
+		} else {
+			p.ch <- C{name}
+		}
+		p.depth++
+	case "property":
+		if p.depth == 0 || p.property {
+			return fmt.Errorf("%v: unexpected element property", ErrInvalidInput)
+		}
+		name, ok := svAttr(e, "name")
+		if !ok {
+			return fmt.Errorf("%v: missing property name", ErrInvalidInput)
+		}
+		typ, ok := svAttr(e, "type")
+		if !ok {
+			return fmt.Errorf("%v: missing property type", ErrInvalidInput)
+		}
+		p.ch <- P{ExportType(typ), name}
+		p.property = true
+		p.binary = JCRType(typ) == "Binary"
+	case "value":
+		if !p.property || p.value != nil {
+			return fmt.Errorf("%v: unexpected element value", ErrInvalidInput)
+		}
+		p.value = &strings.Builder{}
+	default:
+		return fmt.Errorf("%v: unexpected element %v", ErrInvalidInput, e.Name.Local)
+	}
+
+	return nil
+}
+
+func (p *sysViewParser) onEnd() error {
+	switch {
+	case p.value != nil:
+		if err := p.emitValue(p.value.String()); err != nil {
+			return err
+		}
+		p.value = nil
+	case p.property:
+		p.ch <- Up{}
+		p.property = false
+	default:
+		p.ch <- Up{}
+		p.depth--
+	}
+	return nil
+}
+
+func (p *sysViewParser) emitValue(value string) error {
+	if !p.binary {
+		p.ch <- V{value}
+		return nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+	if err != nil {
+		return fmt.Errorf("decoding binary value: %v", err)
+	}
+
+	p.ch <- X{hex.EncodeToString(data)}
+
+	return nil
+}
+
+func isSvName(name xml.Name) bool {
+	return name.Space == SysViewNamespace || name.Space == "sv"
+}
+
+func svAttr(e xml.StartElement, name string) (string, bool) {
+	for _, a := range e.Attr {
+		if isSvName(a.Name) && a.Name.Local == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func parseSysViewAll(s string) []Cmd {
+	var cmds []Cmd
+	for c := range ParseSysView(strings.NewReader(s)) {
+		cmds = append(cmds, c)
+	}
+	return cmds
+}
+
+func TestParseSysView(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+	<sv:node xmlns:sv="http://www.jcp.org/jcr/sv/1.0" sv:name="jcr:root">
+		<sv:property sv:name="jcr:primaryType" sv:type="Name">
+			<sv:value>rep:root</sv:value>
+		</sv:property>
+		<sv:node sv:name="a&amp;b">
+			<sv:property sv:name="c" sv:type="Binary">
+				<sv:value>aGVs
+				bG8=</sv:value>
+			</sv:property>
+			<sv:property sv:name="d" sv:type="String" sv:multiple="true">
+				<sv:value>&lt;e&gt;</sv:value>
+				<sv:value></sv:value>
+			</sv:property>
+			<sv:node sv:name="f"/>
+		</sv:node>
+	</sv:node>`
+
+	expected := []Cmd{
+		R{},
+		P{"name", "jcr:primaryType"},
+		V{"rep:root"},
+		Up{},
+		C{"a&b"},
+		P{"binary", "c"},
+		X{"68656c6c6f"},
+		Up{},
+		P{"string", "d"},
+		V{"<e>"},
+		V{""},
+		Up{},
+		C{"f"},
+		Up{},
+		Up{},
+		Up{},
+	}
+
+	all := parseSysViewAll(doc)
+	if len(all) != len(expected) {
+		t.Fatalf("expected %d commands, got %d: %v\n", len(expected), len(all), all)
+	}
+	for i, a := range expected {
+		if a != all[i] {
+			t.Errorf("expected %v, got %v\n", a, all[i])
+		}
+	}
+}
+
+func TestParseSysViewInvalid(t *testing.T) {
+	tests := []string{
+		`<sv:node xmlns:sv="http://www.jcp.org/jcr/sv/1.0" sv:name="a">`,
+		`<sv:node xmlns:sv="http://www.jcp.org/jcr/sv/1.0"/>`,
+		`<node/>`,
+		`<sv:property xmlns:sv="http://www.jcp.org/jcr/sv/1.0" sv:name="a" sv:type="String"/>`,
+		`<sv:node xmlns:sv="http://www.jcp.org/jcr/sv/1.0" sv:name="a"><sv:value/></sv:node>`,
+		`<sv:node xmlns:sv="http://www.jcp.org/jcr/sv/1.0" sv:name="a"><sv:property sv:name="b"/></sv:node>`,
+		`<sv:node xmlns:sv="http://www.jcp.org/jcr/sv/1.0" sv:name="a"><sv:property sv:name="b" sv:type="Binary"><sv:value>!</sv:value></sv:property></sv:node>`,
+	}
+
+	for _, tt := range tests {
+		all := parseSysViewAll(tt)
+		if len(all) == 0 {
+			t.Errorf("parsing '%v': expected error, got no commands\n", tt)
+			continue
+		}
+		if _, ok := all[len(all)-1].(Err); !ok {
+			t.Errorf("parsing '%v': expected error, got %v\n", tt, all[len(all)-1])
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package parser
+
+import "strings"
+
+var jcrTypes = []string{
+	"String",
+	"Binary",
+	"Long",
+	"Double",
+	"Decimal",
+	"Date",
+	"Boolean",
+	"Name",
+	"Path",
+	"Reference",
+	"WeakReference",
+	"URI",
+	"Undefined",
+}
+
+// JCRType returns the name of the JCR property type corresponding to the type
+// of a property in an export. The comparison between the two types is case
+// insensitive. If the type doesn't correspond to any JCR property type, JCRType
+// returns it unchanged.
+func JCRType(typ string) string {
+	for _, t := range jcrTypes {
+		if strings.EqualFold(t, typ) {
+			return t
+		}
+	}
+	return typ
+}
+
+// ExportType returns the type of a property in an export corresponding to the
+// name of a JCR property type.
+func ExportType(jcrType string) string {
+	return strings.ToLower(jcrType)
+}
@@ -0,0 +1,38 @@
+package parser
+
+import "testing"
+
+func TestJCRType(t *testing.T) {
+	tests := []struct {
+		typ      string
+		expected string
+	}{
+		{"string", "String"},
+		{"STRING", "String"},
+		{"weakreference", "WeakReference"},
+		{"uri", "URI"},
+		{"unknown", "unknown"},
+	}
+
+	for _, tt := range tests {
+		if typ := JCRType(tt.typ); typ != tt.expected {
+			t.Errorf("type %v: expected %v, got %v\n", tt.typ, tt.expected, typ)
+		}
+	}
+}
+
+func TestExportType(t *testing.T) {
+	tests := []struct {
+		typ      string
+		expected string
+	}{
+		{"String", "string"},
+		{"WeakReference", "weakreference"},
+	}
+
+	for _, tt := range tests {
+		if typ := ExportType(tt.typ); typ != tt.expected {
+			t.Errorf("type %v: expected %v, got %v\n", tt.typ, tt.expected, typ)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package paths
+
+import (
+	"fmt"
+	"strings"
+)
+
+// escaped are the characters escaped in a name when it is used as a path
+// component. The percent sign introduces an escape sequence, the slash
+// separates path components, brackets enclose same-name sibling indices, and
+// the vertical bar is reserved.
+const escaped = "%/[]|"
+
+// Escape escapes a name for use as a path component. Every character in
+// `%/[]|` is replaced by a percent sign followed by its code in hexadecimal,
+// like in `%2F`. The names `.` and `..` are escaped entirely, so that they
+// are not confused with the current and parent directories.
+func Escape(name string) string {
+	if name == "." || name == ".." {
+		return strings.Repeat("%2E", len(name))
+	}
+
+	if !strings.ContainsAny(name, escaped) {
+		return name
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(name); i++ {
+		if strings.IndexByte(escaped, name[i]) >= 0 {
+			fmt.Fprintf(&b, "%%%02X", name[i])
+		} else {
+			b.WriteByte(name[i])
+		}
+	}
+
+	return b.String()
+}
+
+// Unescape reverts Escape. Unescape accepts any valid escape sequence, not
+// only the ones produced by Escape. Unescape returns an error if a percent
+// sign is not followed by two hexadecimal digits, or if the component contains
+// an unescaped reserved character.
+func Unescape(component string) (string, error) {
+	if !strings.ContainsAny(component, escaped) {
+		return component, nil
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(component); i++ {
+		switch c := component[i]; c {
+		case '%':
+			if i+2 >= len(component) || !isHex(component[i+1]) || !isHex(component[i+2]) {
+				return "", fmt.Errorf("%v: invalid escape sequence in %v", ErrInvalidPath, component)
+			}
+			b.WriteByte(unhex(component[i+1])<<4 | unhex(component[i+2]))
+			i += 2
+		case '/', '[', ']', '|':
+			return "", fmt.Errorf("%v: unescaped %q in %v", ErrInvalidPath, c, component)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// EscapePath escapes the components of a path whose names are not escaped,
+// like the value of a Path property. The components `.` and `..` are
+// preserved, and so are the leading slash of an absolute path and the
+// same-name sibling indices, like in `/a/b[2]`.
+func EscapePath(path string) string {
+	components := strings.Split(path, "/")
+	for i, c := range components {
+		if c == "." || c == ".." {
+			continue
+		}
+		name, index := c, ""
+		if j := strings.LastIndex(c, "["); j > 0 && strings.HasSuffix(c, "]") && isDecimal(c[j+1:len(c)-1]) {
+			name, index = c[:j], c[j:]
+		}
+		components[i] = Escape(name) + index
+	}
+	return strings.Join(components, "/")
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
@@ -0,0 +1,80 @@
+package paths
+
+import "testing"
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		name, escaped string
+	}{
+		{"a", "a"},
+		{"jcr:content", "jcr:content"},
+		{"a/b", "a%2Fb"},
+		{"a[1]", "a%5B1%5D"},
+		{"a|b", "a%7Cb"},
+		{"100%", "100%25"},
+		{".", "%2E"},
+		{"..", "%2E%2E"},
+		{"...", "..."},
+		{"città", "città"},
+	}
+
+	for _, tt := range tests {
+		if escaped := Escape(tt.name); escaped != tt.escaped {
+			t.Errorf("name %v: expected %v, got %v\n", tt.name, tt.escaped, escaped)
+		}
+		if name, err := Unescape(tt.escaped); err != nil {
+			t.Errorf("component %v: unexpected error: %v\n", tt.escaped, err)
+		} else if name != tt.name {
+			t.Errorf("component %v: expected %v, got %v\n", tt.escaped, tt.name, name)
+		}
+	}
+}
+
+func TestUnescapeInvalid(t *testing.T) {
+	for _, component := range []string{"%", "%2", "%zz", "a%2", "a[1]", "a|b"} {
+		if _, err := Unescape(component); err == nil {
+			t.Errorf("component %v: expected error, got nil\n", component)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		names []string
+		path  string
+	}{
+		{nil, "/"},
+		{[]string{"a"}, "/a"},
+		{[]string{"a/b", "c"}, "/a%2Fb/c"},
+	}
+
+	for _, tt := range tests {
+		path := Format(tt.names)
+		if path != tt.path {
+			t.Errorf("names %v: expected %v, got %v\n", tt.names, tt.path, path)
+		}
+		if components, err := Components(path); err != nil {
+			t.Errorf("path %v: unexpected error: %v\n", path, err)
+		} else if !areStringsEqual(components, tt.names) {
+			t.Errorf("path %v: expected %v, got %v\n", path, tt.names, components)
+		}
+	}
+}
+
+func TestEscapePath(t *testing.T) {
+	tests := []struct {
+		path, escaped string
+	}{
+		{"/", "/"},
+		{"/a/b%c", "/a/b%25c"},
+		{"../a|b/./c", "../a%7Cb/./c"},
+		{"/a/b[2]/c[x]", "/a/b[2]/c%5Bx%5D"},
+		{"[2]", "%5B2%5D"},
+	}
+
+	for _, tt := range tests {
+		if escaped := EscapePath(tt.path); escaped != tt.escaped {
+			t.Errorf("path %v: expected %v, got %v\n", tt.path, tt.escaped, escaped)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalidName is returned when a name is invalid.
+	ErrInvalidName = errors.New("invalid name")
+)
+
+// QName is a name, optionally qualified by a namespace. A name can be
+// unqualified, like `content`, qualified by a namespace prefix, like
+// `jcr:content`, or expanded with a namespace URI, like
+// `{http://www.jcp.org/jcr/1.0}content`.
+type QName struct {
+	// Prefix is the namespace prefix of a qualified name.
+	Prefix string
+	// URI is the namespace URI of an expanded name.
+	URI string
+	// Local is the local name.
+	Local string
+}
+
+// ParseName parses a qualified, expanded, or unqualified name.
+func ParseName(name string) (QName, error) {
+	var q QName
+
+	if strings.HasPrefix(name, "{") {
+		end := strings.Index(name, "}")
+		if end < 0 {
+			return QName{}, fmt.Errorf("%v: unterminated namespace URI: %v", ErrInvalidName, name)
+		}
+		q.URI, q.Local = name[1:end], name[end+1:]
+	} else if i := strings.Index(name, ":"); i >= 0 {
+		q.Prefix, q.Local = name[:i], name[i+1:]
+		if q.Prefix == "" {
+			return QName{}, fmt.Errorf("%v: empty namespace prefix: %v", ErrInvalidName, name)
+		}
+	} else {
+		q.Local = name
+	}
+
+	if q.Local == "" {
+		return QName{}, fmt.Errorf("%v: empty local name: %v", ErrInvalidName, name)
+	}
+
+	return q, nil
+}
+
+// Namespace returns the prefix of a qualified name, the namespace URI of an
+// expanded name enclosed in braces, or an empty string for an unqualified
+// name.
+func (q QName) Namespace() string {
+	if q.URI != "" {
+		return "{" + q.URI + "}"
+	}
+	return q.Prefix
+}
+
+// String returns the name in the form it was parsed from.
+func (q QName) String() string {
+	return q.Namespace() + q.separator() + q.Local
+}
+
+func (q QName) separator() string {
+	if q.Prefix != "" {
+		return ":"
+	}
+	return ""
+}
+
+// ValidateName returns an error if a name can't be used as the name of a node
+// or a property in JCR. A local name can't be empty, `.` or `..`, can't start or
+// end with whitespace, and can't contain any of the characters `/:[]|*` or any
+// character not allowed in XML. A prefix can't contain the same characters.
+func ValidateName(name string) error {
+	q, err := ParseName(name)
+	if err != nil {
+		return err
+	}
+	if q.Prefix != "" {
+		if err := validateNameChars(q.Prefix); err != nil {
+			return fmt.Errorf("%v: prefix %v", ErrInvalidName, err)
+		}
+	}
+	if q.Local == "." || q.Local == ".." {
+		return fmt.Errorf("%v: reserved local name %v", ErrInvalidName, q.Local)
+	}
+	if strings.TrimSpace(q.Local) != q.Local {
+		return fmt.Errorf("%v: local name starts or ends with whitespace", ErrInvalidName)
+	}
+	if err := validateNameChars(q.Local); err != nil {
+		return fmt.Errorf("%v: local name %v", ErrInvalidName, err)
+	}
+	return nil
+}
+
+func validateNameChars(s string) error {
+	if !utf8.ValidString(s) {
+		return errors.New("is not valid UTF-8")
+	}
+	for _, r := range s {
+		if strings.ContainsRune("/:[]|*", r) {
+			return fmt.Errorf("contains %q", r)
+		}
+		if !isXMLChar(r) {
+			return fmt.Errorf("contains %U", r)
+		}
+	}
+	return nil
+}
+
+func isXMLChar(r rune) bool {
+	switch {
+	case r == '\t' || r == '\n' || r == '\r':
+		return true
+	case 0x20 <= r && r <= 0xD7FF:
+		return true
+	case 0xE000 <= r && r <= 0xFFFD:
+		return true
+	case 0x10000 <= r && r <= 0x10FFFF:
+		return true
+	default:
+		return false
+	}
+}
+
+// Remapping maps namespace prefixes to other namespace prefixes.
+type Remapping map[string]string
+
+// ParseRemapping parses a list of remappings in the form `old=new`, where
+// `old` and `new` are namespace prefixes. An empty `new` prefix removes the
+// namespace from the names using `old`.
+func ParseRemapping(specs []string) (Remapping, error) {
+	r := make(Remapping)
+
+	for _, spec := range specs {
+		i := strings.Index(spec, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid remapping: %v", spec)
+		}
+		from, to := spec[:i], spec[i+1:]
+		if strings.ContainsAny(from, ":{}/") || strings.ContainsAny(to, ":{}/") {
+			return nil, fmt.Errorf("invalid remapping: %v", spec)
+		}
+		if _, ok := r[from]; ok {
+			return nil, fmt.Errorf("duplicate remapping: %v", spec)
+		}
+		r[from] = to
+	}
+
+	return r, nil
+}
+
+// Name returns a name with its prefix remapped. Unqualified and expanded names,
+// names with a prefix not in the remapping, and invalid names are returned
+// unchanged.
+func (r Remapping) Name(name string) string {
+	q, err := ParseName(name)
+	if err != nil || q.Prefix == "" {
+		return name
+	}
+	prefix, ok := r[q.Prefix]
+	if !ok {
+		return name
+	}
+	q.Prefix = prefix
+	return q.String()
+}
+
+// Path returns a path, absolute or relative, with the prefix of every
+// component remapped.
+func (r Remapping) Path(path string) string {
+	components := strings.Split(path, "/")
+	for i, c := range components {
+		components[i] = r.Name(c)
+	}
+	return strings.Join(components, "/")
+}
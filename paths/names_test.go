@@ -0,0 +1,113 @@
+package paths
+
+import "testing"
+
+func TestParseName(t *testing.T) {
+	tests := []struct {
+		name      string
+		qname     QName
+		namespace string
+	}{
+		{"content", QName{Local: "content"}, ""},
+		{"jcr:content", QName{Prefix: "jcr", Local: "content"}, "jcr"},
+		{"{http://www.jcp.org/jcr/1.0}content", QName{URI: "http://www.jcp.org/jcr/1.0", Local: "content"}, "{http://www.jcp.org/jcr/1.0}"},
+		{"{}content", QName{Local: "content"}, ""},
+	}
+
+	for _, tt := range tests {
+		qname, err := ParseName(tt.name)
+		if err != nil {
+			t.Errorf("name %v: unexpected error: %v\n", tt.name, err)
+			continue
+		}
+		if qname != tt.qname {
+			t.Errorf("name %v: expected %+v, got %+v\n", tt.name, tt.qname, qname)
+		}
+		if ns := qname.Namespace(); ns != tt.namespace {
+			t.Errorf("name %v: expected namespace %v, got %v\n", tt.name, tt.namespace, ns)
+		}
+	}
+}
+
+func TestParseNameInvalid(t *testing.T) {
+	for _, name := range []string{"", ":a", "a:", "{http://a", "{http://a}"} {
+		if _, err := ParseName(name); err == nil {
+			t.Errorf("name %v: expected error, got nil\n", name)
+		}
+	}
+}
+
+func TestRemapping(t *testing.T) {
+	r, err := ParseRemapping([]string{"old=new", "gone="})
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	tests := []struct {
+		input, name, path string
+	}{
+		{"old:a", "new:a", "new:a"},
+		{"gone:a", "a", "a"},
+		{"other:a", "other:a", "other:a"},
+		{"a", "a", "a"},
+		{"/old:a/b/gone:c", "/old:a/b/gone:c", "/new:a/b/c"},
+		{"../old:a", "../old:a", "../new:a"},
+	}
+
+	for _, tt := range tests {
+		if name := r.Name(tt.input); name != tt.name {
+			t.Errorf("name %v: expected %v, got %v\n", tt.input, tt.name, name)
+		}
+		if path := r.Path(tt.input); path != tt.path {
+			t.Errorf("path %v: expected %v, got %v\n", tt.input, tt.path, path)
+		}
+	}
+}
+
+func TestParseRemappingInvalid(t *testing.T) {
+	tests := [][]string{
+		{"old"},
+		{"=new"},
+		{"old:=new"},
+		{"a=b", "a=c"},
+	}
+
+	for _, specs := range tests {
+		if _, err := ParseRemapping(specs); err == nil {
+			t.Errorf("remapping %v: expected error, got nil\n", specs)
+		}
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"a", true},
+		{"jcr:content", true},
+		{"{http://example.com}a", true},
+		{"a b", true},
+		{"città", true},
+		{"", false},
+		{".", false},
+		{"..", false},
+		{"jcr:..", false},
+		{" a", false},
+		{"a ", false},
+		{"a/b", false},
+		{"a:b:c", false},
+		{"a[1]", false},
+		{"a|b", false},
+		{"a*", false},
+		{"a\x00", false},
+		{"a\xff", false},
+		{"p*:a", false},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateName(tt.name); (err == nil) != tt.valid {
+			t.Errorf("name %q: expected valid %v, got error %v\n", tt.name, tt.valid, err)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package paths
+
+import (
+	"path"
+	"strings"
+)
+
+// Path is an absolute path, as the list of its segments from the root. The
+// root is the empty Path. A Path is always normalized: it doesn't contain the
+// components `.` and `..`, and it never goes above the root. Paths are values,
+// and methods returning a Path never modify their receiver.
+type Path []Segment
+
+// Root is the path of the root.
+var Root Path
+
+// Parse parses an absolute path. Components are unescaped as described in
+// Unescape, and can be followed by a same-name sibling index in brackets, like
+// in `/a/b[2]`. A component without an index has index 1. The components `.`
+// are removed, and the components `..` remove the component preceding them,
+// if any.
+func Parse(s string) (Path, error) {
+	components, err := split(s)
+	if err != nil {
+		return nil, err
+	}
+	return Root.appendComponents(components)
+}
+
+// MustParse is like Parse, but panics if the path is invalid.
+func MustParse(s string) Path {
+	p, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p Path) appendComponents(components []string) (Path, error) {
+	result := append(Path(nil), p...)
+
+	for _, c := range components {
+		switch c {
+		case "", ".":
+			continue
+		case "..":
+			result = result.Parent()
+			continue
+		}
+		s, err := parseSegment(c)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+
+	return result, nil
+}
+
+// String returns the path with every name escaped, and with the index of the
+// segments whose index is greater than 1.
+func (p Path) String() string {
+	components := make([]string, len(p))
+	for i, s := range p {
+		components[i] = s.String()
+	}
+	return "/" + strings.Join(components, "/")
+}
+
+// IsRoot tells if the path is the root.
+func (p Path) IsRoot() bool {
+	return len(p) == 0
+}
+
+// Depth returns the number of segments in the path. The depth of the root is
+// zero.
+func (p Path) Depth() int {
+	return len(p)
+}
+
+// Name returns the name of the last segment of the path, or an empty string if
+// the path is the root.
+func (p Path) Name() string {
+	if len(p) == 0 {
+		return ""
+	}
+	return p[len(p)-1].Name
+}
+
+// Names returns the names of the segments of the path.
+func (p Path) Names() []string {
+	var names []string
+	for _, s := range p {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+// Parent returns the path of the parent. The parent of the root is the root.
+func (p Path) Parent() Path {
+	if len(p) <= 1 {
+		return Root
+	}
+	return p[: len(p)-1 : len(p)-1]
+}
+
+// Child returns the path of the child of p with the specified name and
+// same-name sibling index.
+func (p Path) Child(name string, index int) Path {
+	child := make(Path, len(p), len(p)+1)
+	copy(child, p)
+	return append(child, Segment{Name: name, Index: index})
+}
+
+// Equal tells if two paths are the same path.
+func (p Path) Equal(q Path) bool {
+	if len(p) != len(q) {
+		return false
+	}
+	for i := range p {
+		if p[i] != q[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// IsAncestor tells if p is an ancestor of q. A path is not an ancestor of
+// itself.
+func (p Path) IsAncestor(q Path) bool {
+	return len(p) < len(q) && p.Equal(q[:len(p)])
+}
+
+// Contains tells if q is p or one of its descendants.
+func (p Path) Contains(q Path) bool {
+	return len(p) <= len(q) && p.Equal(q[:len(p)])
+}
+
+// Resolve resolves a path relative to p. If rel is absolute, Resolve is
+// equivalent to Parse. An empty rel resolves to p.
+func (p Path) Resolve(rel string) (Path, error) {
+	if strings.HasPrefix(rel, "/") {
+		return Parse(rel)
+	}
+	return p.appendComponents(strings.Split(rel, "/"))
+}
+
+// Rel returns a relative path that, when resolved relative to p, addresses q.
+// Rel returns `.` if p and q are the same path.
+func (p Path) Rel(q Path) string {
+	common := 0
+	for common < len(p) && common < len(q) && p[common] == q[common] {
+		common++
+	}
+
+	var components []string
+
+	for range p[common:] {
+		components = append(components, "..")
+	}
+	for _, s := range q[common:] {
+		components = append(components, s.String())
+	}
+
+	if len(components) == 0 {
+		return "."
+	}
+
+	return strings.Join(components, "/")
+}
+
+// Clean returns the shortest path equivalent to p by lexical processing, like
+// path.Clean, and removes the redundant same-name sibling index 1 from every
+// component. Clean doesn't validate the components of the path.
+func Clean(p string) string {
+	cleaned := path.Clean(p)
+	if !strings.Contains(cleaned, "[1]") {
+		return cleaned
+	}
+	components := strings.Split(cleaned, "/")
+	for i, c := range components {
+		components[i] = strings.TrimSuffix(c, "[1]")
+	}
+	return strings.Join(components, "/")
+}
+
+// Join joins any number of path elements into a single path, and cleans the
+// result as described in Clean. Empty elements are ignored. Like path.Join,
+// Join doesn't treat absolute elements after the first one differently.
+func Join(elem ...string) string {
+	joined := path.Join(elem...)
+	if joined == "" {
+		return ""
+	}
+	return Clean(joined)
+}
+
+// Parent returns the path of the parent of p, after cleaning p. The parent of
+// the root is the root.
+func Parent(p string) string {
+	return path.Dir(Clean(p))
+}
+
+// Name returns the last component of p, escaped and with its same-name sibling
+// index if any, after cleaning p. Name returns an empty string for the root.
+func Name(p string) string {
+	cleaned := Clean(p)
+	if cleaned == "/" {
+		return ""
+	}
+	return path.Base(cleaned)
+}
+
+// IsAncestor tells if ancestor is an ancestor of p, after cleaning both of
+// them. A path is not an ancestor of itself.
+func IsAncestor(ancestor, p string) bool {
+	ancestor, p = Clean(ancestor), Clean(p)
+	if ancestor == p {
+		return false
+	}
+	if ancestor == "/" {
+		return strings.HasPrefix(p, "/")
+	}
+	return strings.HasPrefix(p, ancestor+"/")
+}
+
+// Contains tells if p is ancestor or one of its descendants, after cleaning
+// both of them.
+func Contains(ancestor, p string) bool {
+	return Clean(ancestor) == Clean(p) || IsAncestor(ancestor, p)
+}
+
+// Rel returns a relative path that, when joined to base, addresses target.
+// Both base and target must be absolute paths.
+func Rel(base, target string) (string, error) {
+	b, err := Parse(base)
+	if err != nil {
+		return "", err
+	}
+	t, err := Parse(target)
+	if err != nil {
+		return "", err
+	}
+	return b.Rel(t), nil
+}
+
+// Format returns the absolute path of a list of names, escaping every name.
+func Format(names []string) string {
+	p := make(Path, len(names))
+	for i, name := range names {
+		p[i] = Segment{Name: name, Index: 1}
+	}
+	return p.String()
+}
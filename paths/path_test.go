@@ -0,0 +1,176 @@
+package paths
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		path string
+		p    Path
+	}{
+		{"/", nil},
+		{"/a", Path{{"a", 1}}},
+		{"/a[1]/b[2]", Path{{"a", 1}, {"b", 2}}},
+		{"/a%5B2%5D[3]", Path{{"a[2]", 3}}},
+		{"/jcr:content[10]", Path{{"jcr:content", 10}}},
+		{"/a/./b", Path{{"a", 1}, {"b", 1}}},
+		{"/a/../b", Path{{"b", 1}}},
+		{"/a/b/../..", nil},
+		{"/..", nil},
+		{"/a/%2E%2E", Path{{"a", 1}, {"..", 1}}},
+	}
+
+	for _, tt := range tests {
+		p, err := Parse(tt.path)
+		if err != nil {
+			t.Errorf("path %v: unexpected error: %v\n", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(p, tt.p) {
+			t.Errorf("path %v: expected %v, got %v\n", tt.path, tt.p, p)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, path := range []string{"", "a", "/a[0]", "/a[]", "/a[-1]", "/a[+1]", "/a[x]", "/[2]", "/a]", "/a[1][2]", "/a[2"} {
+		if _, err := Parse(path); err == nil {
+			t.Errorf("path %v: expected error, got nil\n", path)
+		}
+	}
+}
+
+func TestComponentsIndex(t *testing.T) {
+	if components, err := Components("/a[1]/b"); err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	} else if !areStringsEqual(components, []string{"a", "b"}) {
+		t.Errorf("expected [a b], got %v\n", components)
+	}
+	if _, err := Components("/a[2]/b"); err == nil {
+		t.Errorf("expected error, got nil\n")
+	}
+}
+
+func TestPathMethods(t *testing.T) {
+	p := MustParse("/a/b%2Fc[2]")
+
+	if s := p.String(); s != "/a/b%2Fc[2]" {
+		t.Errorf("string: expected /a/b%%2Fc[2], got %v\n", s)
+	}
+	if name := p.Name(); name != "b/c" {
+		t.Errorf("name: expected b/c, got %v\n", name)
+	}
+	if depth := p.Depth(); depth != 2 {
+		t.Errorf("depth: expected 2, got %v\n", depth)
+	}
+	if parent := p.Parent(); parent.String() != "/a" {
+		t.Errorf("parent: expected /a, got %v\n", parent)
+	}
+	if parent := Root.Parent(); !parent.IsRoot() {
+		t.Errorf("parent of root: expected /, got %v\n", parent)
+	}
+	if child := p.Parent().Child("d", 1); child.String() != "/a/d" || p.String() != "/a/b%2Fc[2]" {
+		t.Errorf("child: expected /a/d, got %v, and %v unchanged\n", child, p)
+	}
+	if !Root.IsAncestor(p) || !p.Parent().IsAncestor(p) || p.IsAncestor(p) || p.IsAncestor(p.Parent()) {
+		t.Errorf("is ancestor: unexpected result\n")
+	}
+	if !p.Contains(p) || !Root.Contains(p) || p.Contains(p.Parent()) || MustParse("/a/b").Contains(p) {
+		t.Errorf("contains: unexpected result\n")
+	}
+}
+
+func TestPathResolve(t *testing.T) {
+	base := MustParse("/a/b")
+
+	tests := []struct {
+		rel, path string
+	}{
+		{"", "/a/b"},
+		{".", "/a/b"},
+		{"c", "/a/b/c"},
+		{"../c[2]", "/a/c[2]"},
+		{"../../../c", "/c"},
+		{"/d", "/d"},
+	}
+
+	for _, tt := range tests {
+		p, err := base.Resolve(tt.rel)
+		if err != nil {
+			t.Errorf("rel %v: unexpected error: %v\n", tt.rel, err)
+		} else if p.String() != tt.path {
+			t.Errorf("rel %v: expected %v, got %v\n", tt.rel, tt.path, p)
+		}
+	}
+}
+
+func TestRel(t *testing.T) {
+	tests := []struct {
+		base, target, rel string
+	}{
+		{"/a/b", "/a/b", "."},
+		{"/a/b", "/a/b/c", "c"},
+		{"/a/b", "/a/c", "../c"},
+		{"/a/b", "/", "../.."},
+		{"/a/b[2]", "/a/b/c", "../b/c"},
+	}
+
+	for _, tt := range tests {
+		rel, err := Rel(tt.base, tt.target)
+		if err != nil {
+			t.Errorf("rel %v %v: unexpected error: %v\n", tt.base, tt.target, err)
+			continue
+		}
+		if rel != tt.rel {
+			t.Errorf("rel %v %v: expected %v, got %v\n", tt.base, tt.target, tt.rel, rel)
+		}
+		if p, _ := MustParse(tt.base).Resolve(rel); p.String() != Clean(tt.target) {
+			t.Errorf("rel %v %v: resolved to %v\n", tt.base, tt.target, p)
+		}
+	}
+}
+
+func TestStringFunctions(t *testing.T) {
+	tests := []struct {
+		name, got, expected string
+	}{
+		{"clean", Clean("/a/./b[1]/../c//"), "/a/c"},
+		{"clean relative", Clean("../a[1]"), "../a"},
+		{"join", Join("/a", "b[1]", "../c[2]"), "/a/c[2]"},
+		{"join empty", Join(), ""},
+		{"parent", Parent("/a/b"), "/a"},
+		{"parent of root", Parent("/"), "/"},
+		{"name", Name("/a/b%2Fc[2]"), "b%2Fc[2]"},
+		{"name of root", Name("/"), ""},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.expected {
+			t.Errorf("%v: expected %v, got %v\n", tt.name, tt.expected, tt.got)
+		}
+	}
+
+	ancestors := []struct {
+		ancestor, p          string
+		isAncestor, contains bool
+	}{
+		{"/", "/a", true, true},
+		{"/", "/", false, true},
+		{"/a", "/a/b", true, true},
+		{"/a", "/ab", false, false},
+		{"/a[1]", "/a/b", true, true},
+		{"/a[2]", "/a/b", false, false},
+		{"/a/b", "/a", false, false},
+	}
+
+	for _, tt := range ancestors {
+		if got := IsAncestor(tt.ancestor, tt.p); got != tt.isAncestor {
+			t.Errorf("is ancestor %v %v: expected %v, got %v\n", tt.ancestor, tt.p, tt.isAncestor, got)
+		}
+		if got := Contains(tt.ancestor, tt.p); got != tt.contains {
+			t.Errorf("contains %v %v: expected %v, got %v\n", tt.ancestor, tt.p, tt.contains, got)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package paths
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"strings"
 )
@@ -11,8 +12,27 @@ var (
 	ErrInvalidPath = errors.New("invalid path")
 )
 
-// Components returns the components of a fully qualified path.
+// Components returns the names of the components of a fully qualified path,
+// parsed as described in Parse. A component can have the same-name sibling
+// index 1, like in `/a/b[1]`, but Components returns an error for greater
+// indices, because the names alone can't address such a node. Use Parse to
+// address same-name siblings.
 func Components(path string) ([]string, error) {
+	p, err := Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range p {
+		if s.Index != 1 {
+			return nil, fmt.Errorf("%v: same-name sibling index not supported: %v", ErrInvalidPath, path)
+		}
+	}
+	return p.Names(), nil
+}
+
+// split returns the components of a fully qualified path, without unescaping
+// them.
+func split(path string) ([]string, error) {
 	const (
 		stateStart = iota
 		stateEnd
@@ -0,0 +1,105 @@
+package paths
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Segment is a component of a path addressing a node among its same-name
+// siblings. Index is the position of the node among the siblings with the
+// same name, starting from 1.
+type Segment struct {
+	Name  string
+	Index int
+}
+
+// String returns the escaped name of the segment, followed by its index in
+// brackets if the index is greater than 1, like in `b[2]`.
+func (s Segment) String() string {
+	if s.Index > 1 {
+		return fmt.Sprintf("%v[%d]", Escape(s.Name), s.Index)
+	}
+	return Escape(s.Name)
+}
+
+func parseSegment(component string) (Segment, error) {
+	name, index := component, 1
+
+	if strings.HasSuffix(component, "]") {
+		i := strings.LastIndex(component, "[")
+		if i < 0 {
+			return Segment{}, fmt.Errorf("%v: unescaped ']' in %v", ErrInvalidPath, component)
+		}
+		digits := component[i+1 : len(component)-1]
+		if !isDecimal(digits) {
+			return Segment{}, fmt.Errorf("%v: invalid index in %v", ErrInvalidPath, component)
+		}
+		n, err := strconv.Atoi(digits)
+		if err != nil || n < 1 {
+			return Segment{}, fmt.Errorf("%v: invalid index in %v", ErrInvalidPath, component)
+		}
+		name, index = component[:i], n
+	}
+
+	if name == "" {
+		return Segment{}, fmt.Errorf("%v: empty name in %v", ErrInvalidPath, component)
+	}
+
+	name, err := Unescape(name)
+	if err != nil {
+		return Segment{}, err
+	}
+
+	return Segment{Name: name, Index: index}, nil
+}
+
+func isDecimal(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Siblings assigns same-name sibling indices to the nodes of a tree visited in
+// depth-first order. Every call to Enter or EnterProperty must be matched by a
+// call to Leave when the node or the property ends.
+type Siblings struct {
+	// counts has, for every open node or property, the number of children
+	// read so far for every name.
+	counts []map[string]int
+}
+
+// Enter enters a child node of the current node, or the root node if there is
+// no current node, and returns its index among the siblings with the same
+// name.
+func (s *Siblings) Enter(name string) int {
+	index := 1
+
+	if n := len(s.counts); n > 0 {
+		if s.counts[n-1] == nil {
+			s.counts[n-1] = make(map[string]int)
+		}
+		s.counts[n-1][name]++
+		index = s.counts[n-1][name]
+	}
+
+	s.counts = append(s.counts, nil)
+
+	return index
+}
+
+// EnterProperty enters a property of the current node. Properties don't have
+// same-name siblings, and don't affect the indices of child nodes.
+func (s *Siblings) EnterProperty() {
+	s.counts = append(s.counts, nil)
+}
+
+// Leave leaves the current node or property.
+func (s *Siblings) Leave() {
+	if len(s.counts) > 0 {
+		s.counts = s.counts[:len(s.counts)-1]
+	}
+}
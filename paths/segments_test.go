@@ -0,0 +1,43 @@
+package paths
+
+import "testing"
+
+func TestSegmentString(t *testing.T) {
+	tests := []struct {
+		segment Segment
+		s       string
+	}{
+		{Segment{"a", 1}, "a"},
+		{Segment{"a", 2}, "a[2]"},
+		{Segment{"b/c", 3}, "b%2Fc[3]"},
+	}
+
+	for _, tt := range tests {
+		if s := tt.segment.String(); s != tt.s {
+			t.Errorf("segment %+v: expected %v, got %v\n", tt.segment, tt.s, s)
+		}
+	}
+}
+
+func TestSiblings(t *testing.T) {
+	var s Siblings
+
+	s.Enter("")
+	if i := s.Enter("a"); i != 1 {
+		t.Errorf("expected 1, got %v\n", i)
+	}
+	s.Leave()
+	s.EnterProperty()
+	s.Leave()
+	if i := s.Enter("a"); i != 2 {
+		t.Errorf("expected 2, got %v\n", i)
+	}
+	if i := s.Enter("a"); i != 1 {
+		t.Errorf("expected 1, got %v\n", i)
+	}
+	s.Leave()
+	s.Leave()
+	if i := s.Enter("b"); i != 1 {
+		t.Errorf("expected 1, got %v\n", i)
+	}
+}
@@ -0,0 +1,319 @@
+package query
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/paths"
+	"github.com/francescomari/nu/transform"
+)
+
+// Row is a row produced by a query, or an error if the evaluation fails.
+type Row struct {
+	// Values are the values of the columns of the row.
+	Values []string
+
+	Err  error
+	Line int
+}
+
+// row is a node being evaluated.
+type row struct {
+	path       string
+	name       string
+	depth      int
+	size       int64
+	properties map[string]*rowProperty
+}
+
+type rowProperty struct {
+	values []transform.Value
+}
+
+// values returns the values of a field. Binary values are excluded.
+func (r *row) values(f field) []string {
+	switch f.kind {
+	case fieldPath:
+		return []string{r.path}
+	case fieldName:
+		return []string{r.name}
+	case fieldDepth:
+		return []string{strconv.Itoa(r.depth)}
+	case fieldSize:
+		return []string{strconv.FormatInt(r.size, 10)}
+	}
+
+	p, ok := r.properties[f.name]
+	if !ok {
+		return nil
+	}
+
+	var values []string
+	for _, v := range p.values {
+		if !v.Binary {
+			values = append(values, v.Data)
+		}
+	}
+	return values
+}
+
+func (r *row) isNull(f field) bool {
+	if f.kind != fieldProperty {
+		return false
+	}
+	_, ok := r.properties[f.name]
+	return !ok
+}
+
+// format formats the value of a field for a projection. Multiple values are
+// enclosed in square brackets, and binary values are summarized by their size.
+// The value of a missing property is empty.
+func (r *row) format(f field) string {
+	if f.kind != fieldProperty {
+		return r.values(f)[0]
+	}
+
+	p, ok := r.properties[f.name]
+	if !ok {
+		return ""
+	}
+
+	values := make([]string, len(p.values))
+	for i, v := range p.values {
+		if v.Binary {
+			values[i] = fmt.Sprintf("<binary, %v bytes>", hex.DecodedLen(len(v.Data)))
+		} else {
+			values[i] = v.Data
+		}
+	}
+
+	if len(values) == 1 {
+		return values[0]
+	}
+
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// Evaluate evaluates a plan over a stream of commands. A node is evaluated as
+// soon as its properties have been read, that is when its first child or the
+// end of its subtree is read. Properties following a child node are therefore
+// not supported, and Evaluate emits an error for them. If the columns are
+// aggregates, a single row is emitted when the evaluation is complete.
+// Evaluate returns as soon as the scanned subtree ends, without reading the
+// rest of the stream.
+func Evaluate(plan *Plan, commands <-chan parser.Cmd) <-chan Row {
+	results := make(chan Row)
+
+	go func() {
+		defer close(results)
+
+		e := evaluator{
+			plan:    plan,
+			results: results,
+			sums:    make([]float64, len(plan.query.columns)),
+		}
+
+		if !plan.empty {
+			for command := range commands {
+				if done := e.evaluate(command); done {
+					break
+				}
+			}
+		}
+
+		if plan.aggregate && e.err == nil {
+			results <- Row{Values: e.aggregates()}
+		}
+	}()
+
+	return results
+}
+
+type frame struct {
+	row *row
+	// inScope is true if the node is a row of the query.
+	inScope bool
+	// evaluated is true if the node has already been evaluated.
+	evaluated bool
+}
+
+type evaluator struct {
+	plan       *Plan
+	results    chan<- Row
+	path       paths.Path
+	siblings   paths.Siblings
+	frames     []*frame
+	inProperty bool
+	property   *rowProperty
+	rows       int
+	sums       []float64
+	err        error
+}
+
+// evaluate processes a command and returns true if the evaluation is complete.
+func (e *evaluator) evaluate(command parser.Cmd) bool {
+	switch cmd := command.(type) {
+	case parser.R:
+		e.push("")
+	case parser.C:
+		if len(e.frames) > 0 {
+			e.evaluateFrame(e.frames[len(e.frames)-1])
+		}
+		if e.inProperty {
+			e.inProperty = false
+			e.property = nil
+			e.siblings.Leave()
+		}
+		e.push(cmd.Name)
+	case parser.P:
+		if len(e.frames) == 0 {
+			return false
+		}
+		if !e.inProperty {
+			e.siblings.EnterProperty()
+		}
+		e.inProperty = true
+		e.property = nil
+		top := e.frames[len(e.frames)-1]
+		if !top.inScope {
+			return false
+		}
+		if top.evaluated {
+			return e.fail(Row{Err: fmt.Errorf("%v/%v: property follows a child node", strings.TrimSuffix(top.row.path, "/"), cmd.Name)})
+		}
+		if e.plan.properties[cmd.Name] {
+			e.property = &rowProperty{}
+			top.row.properties[cmd.Name] = e.property
+		}
+	case parser.V:
+		e.addValue(transform.Value{Data: cmd.Data}, int64(len(cmd.Data)))
+	case parser.X:
+		e.addValue(transform.Value{Data: cmd.Data, Binary: true}, int64(hex.DecodedLen(len(cmd.Data))))
+	case parser.Up:
+		if e.inProperty {
+			e.inProperty = false
+			e.property = nil
+			e.siblings.Leave()
+			return false
+		}
+		if len(e.frames) == 0 {
+			return false
+		}
+		top := e.frames[len(e.frames)-1]
+		e.evaluateFrame(top)
+		e.frames = e.frames[:len(e.frames)-1]
+		e.siblings.Leave()
+		if len(e.frames) > 0 {
+			e.path = e.path[:len(e.path)-1]
+		}
+		// The scanned subtree ends with the node at its root.
+		return top.inScope && top.row.depth == e.plan.root.Depth()
+	case parser.Err:
+		return e.fail(Row{Err: cmd.Err, Line: cmd.Line})
+	}
+	return false
+}
+
+func (e *evaluator) fail(r Row) bool {
+	e.err = r.Err
+	e.results <- r
+	return true
+}
+
+func (e *evaluator) push(name string) {
+	index := e.siblings.Enter(name)
+	if len(e.frames) > 0 {
+		e.path = append(e.path, paths.Segment{Name: name, Index: index})
+	}
+
+	f := frame{inScope: e.isInScope()}
+
+	if f.inScope {
+		f.row = &row{
+			path:       e.path.String(),
+			name:       name,
+			depth:      e.path.Depth(),
+			properties: make(map[string]*rowProperty),
+		}
+	}
+
+	e.frames = append(e.frames, &f)
+}
+
+func (e *evaluator) isInScope() bool {
+	if e.plan.maxDepth >= 0 && e.path.Depth() > e.plan.maxDepth {
+		return false
+	}
+	return e.plan.root.Contains(e.path)
+}
+
+func (e *evaluator) addValue(v transform.Value, size int64) {
+	if !e.inProperty || len(e.frames) == 0 {
+		return
+	}
+	top := e.frames[len(e.frames)-1]
+	if !top.inScope {
+		return
+	}
+	top.row.size += size
+	if e.property != nil {
+		e.property.values = append(e.property.values, v)
+	}
+}
+
+func (e *evaluator) evaluateFrame(f *frame) {
+	if !f.inScope || f.evaluated {
+		return
+	}
+
+	f.evaluated = true
+
+	if e.plan.query.where != nil && !e.plan.query.where.eval(f.row) {
+		return
+	}
+
+	if e.plan.aggregate {
+		e.accumulate(f.row)
+		return
+	}
+
+	values := make([]string, len(e.plan.query.columns))
+	for i, c := range e.plan.query.columns {
+		values[i] = f.row.format(*c.field)
+	}
+	e.results <- Row{Values: values}
+}
+
+func (e *evaluator) accumulate(r *row) {
+	e.rows++
+
+	for i, c := range e.plan.query.columns {
+		switch c.aggregate {
+		case aggregateCount:
+			if c.field != nil && !r.isNull(*c.field) {
+				e.sums[i]++
+			}
+		case aggregateSum:
+			for _, v := range r.values(*c.field) {
+				if n, err := strconv.ParseFloat(v, 64); err == nil {
+					e.sums[i] += n
+				}
+			}
+		}
+	}
+}
+
+func (e *evaluator) aggregates() []string {
+	values := make([]string, len(e.plan.query.columns))
+	for i, c := range e.plan.query.columns {
+		if c.aggregate == aggregateCount && c.field == nil {
+			values[i] = strconv.Itoa(e.rows)
+		} else {
+			values[i] = strconv.FormatFloat(e.sums[i], 'f', -1, 64)
+		}
+	}
+	return values
+}
@@ -0,0 +1,193 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+const evalExport = `
+	r
+	c content
+	c site
+	p Name jcr:primaryType
+	v cq:Page
+	p String jcr:title
+	v Site
+	c en
+	p Name jcr:primaryType
+	v cq:Page
+	p String jcr:title
+	v
+	p Binary thumbnail
+	x cafebabe
+	^
+	^
+	c es
+	p Name jcr:primaryType
+	v cq:Page
+	p String tags
+	v a
+	v b
+	^
+	^
+	^
+	c assets
+	p Name jcr:primaryType
+	v sling:Folder
+	^
+	^
+	^
+	c apps
+	^
+	^
+`
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		query string
+		rows  [][]string
+	}{
+		{
+			"select *",
+			[][]string{{"/"}, {"/content"}, {"/content/site"}, {"/content/site/en"}, {"/content/site/es"}, {"/content/assets"}, {"/apps"}},
+		},
+		{
+			"select path from '/content' where [jcr:primaryType] = 'cq:Page' and ([jcr:title] = '' or [jcr:title] is null)",
+			[][]string{{"/content/site/en"}, {"/content/site/es"}},
+		},
+		{
+			"select name, depth, size, jcr:title, thumbnail, tags from '/content/site' where depth > 2",
+			[][]string{{"en", "3", "11", "", "<binary, 4 bytes>", ""}, {"es", "3", "9", "", "", "[a, b]"}},
+		},
+		{
+			"select path where tags = 'b'",
+			[][]string{{"/content/site/es"}},
+		},
+		{
+			"select path where path like '/content/%/e_'",
+			[][]string{{"/content/site/en"}, {"/content/site/es"}},
+		},
+		{
+			"select path where depth <= 1 and not name = ''",
+			[][]string{{"/content"}, {"/apps"}},
+		},
+		{
+			"select count(*), count(jcr:title), sum(size), sum(depth) where jcr:primaryType like 'cq:%'",
+			[][]string{{"3", "2", "31", "8"}},
+		},
+		{
+			"select count(*) from '/missing'",
+			[][]string{{"0"}},
+		},
+		{
+			"select * from '/a' where path like '/b/%'",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		q, err := Parse(tt.query)
+		if err != nil {
+			t.Errorf("query %q: unexpected error: %v\n", tt.query, err)
+			continue
+		}
+		p, err := NewPlan(q)
+		if err != nil {
+			t.Errorf("query %q: unexpected error: %v\n", tt.query, err)
+			continue
+		}
+		var rows [][]string
+		for r := range Evaluate(p, parser.Parse(strings.NewReader(evalExport))) {
+			if r.Err != nil {
+				t.Fatalf("query %q: error at line %v: %v\n", tt.query, r.Line, r.Err)
+			}
+			rows = append(rows, r.Values)
+		}
+		if !reflect.DeepEqual(rows, tt.rows) {
+			t.Errorf("query %q: expected %v, got %v\n", tt.query, tt.rows, rows)
+		}
+	}
+}
+
+func TestEvaluatePropertyAfterChild(t *testing.T) {
+	export := `
+		r
+		c a
+		^
+		p String b
+		v c
+		^
+		^
+	`
+
+	q, err := Parse("select *")
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	p, err := NewPlan(q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	var failed bool
+	for r := range Evaluate(p, parser.Parse(strings.NewReader(export))) {
+		if r.Err != nil {
+			failed = true
+		}
+	}
+	if !failed {
+		t.Errorf("expected error, got none\n")
+	}
+}
+
+func TestEvaluateSameNameSiblings(t *testing.T) {
+	export := `
+		r
+		c a
+		c x
+		^
+		^
+		c a
+		c y
+		^
+		^
+		^
+	`
+
+	tests := []struct {
+		query string
+		rows  [][]string
+	}{
+		{"select path", [][]string{{"/"}, {"/a"}, {"/a/x"}, {"/a[2]"}, {"/a[2]/y"}}},
+		{"select path from '/a[2]'", [][]string{{"/a[2]"}, {"/a[2]/y"}}},
+		{"select path from '/a'", [][]string{{"/a"}, {"/a/x"}}},
+		{"select name where path = '/a[2]/y'", [][]string{{"y"}}},
+		{"select path from '/a[3]'", nil},
+	}
+
+	for _, tt := range tests {
+		q, err := Parse(tt.query)
+		if err != nil {
+			t.Errorf("query %q: unexpected error: %v\n", tt.query, err)
+			continue
+		}
+		p, err := NewPlan(q)
+		if err != nil {
+			t.Errorf("query %q: unexpected error: %v\n", tt.query, err)
+			continue
+		}
+		var rows [][]string
+		for r := range Evaluate(p, parser.Parse(strings.NewReader(export))) {
+			if r.Err != nil {
+				t.Fatalf("query %q: error at line %v: %v\n", tt.query, r.Line, r.Err)
+			}
+			rows = append(rows, r.Values)
+		}
+		if !reflect.DeepEqual(rows, tt.rows) {
+			t.Errorf("query %q: expected %v, got %v\n", tt.query, tt.rows, rows)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	// tokenWord is a keyword or a bare name, like `select` or `jcr:title`.
+	tokenWord
+	// tokenName is a name enclosed in square brackets, like `[jcr:title]`.
+	tokenName
+	// tokenString is a string literal enclosed in single quotes.
+	tokenString
+	// tokenNumber is a numeric literal.
+	tokenNumber
+	// tokenSymbol is a punctuation or an operator, like `(` or `<=`.
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenName:
+		return "[" + t.text + "]"
+	case tokenString:
+		return "'" + strings.Replace(t.text, "'", "''", -1) + "'"
+	default:
+		return t.text
+	}
+}
+
+// is returns true if the token is a word or a symbol equal to text. Words are
+// compared case-insensitively.
+func (t token) is(text string) bool {
+	switch t.kind {
+	case tokenWord:
+		return strings.EqualFold(t.text, text)
+	case tokenSymbol:
+		return t.text == text
+	default:
+		return false
+	}
+}
+
+var symbols = []string{"<=", ">=", "<>", "!=", "=", "<", ">", "(", ")", ",", "*"}
+
+// lex splits a query into tokens. The last token is always tokenEOF.
+func lex(q string) ([]token, error) {
+	var (
+		tokens []token
+		runes  = []rune(q)
+		i      = 0
+	)
+
+	for {
+		for i < len(runes) && unicode.IsSpace(runes[i]) {
+			i++
+		}
+
+		if i == len(runes) {
+			return append(tokens, token{kind: tokenEOF, pos: i}), nil
+		}
+
+		start := i
+
+		switch c := runes[i]; {
+		case c == '\'':
+			var b strings.Builder
+			for i++; ; i++ {
+				if i == len(runes) {
+					return nil, fmt.Errorf("%v at position %v: unterminated string", ErrInvalidQuery, start)
+				}
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						i++
+					} else {
+						break
+					}
+				}
+				b.WriteRune(runes[i])
+			}
+			i++
+			tokens = append(tokens, token{tokenString, b.String(), start})
+		case c == '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("%v at position %v: unterminated name", ErrInvalidQuery, start)
+			}
+			if end == i+1 {
+				return nil, fmt.Errorf("%v at position %v: empty name", ErrInvalidQuery, start)
+			}
+			tokens = append(tokens, token{tokenName, string(runes[i+1 : end]), start})
+			i = end + 1
+		case unicode.IsDigit(c) || c == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			for i++; i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.'); i++ {
+			}
+			tokens = append(tokens, token{tokenNumber, string(runes[start:i]), start})
+		case isWordStart(c):
+			for i++; i < len(runes) && isWordPart(runes[i]); i++ {
+			}
+			tokens = append(tokens, token{tokenWord, string(runes[start:i]), start})
+		default:
+			symbol := ""
+			for _, s := range symbols {
+				if strings.HasPrefix(string(runes[i:]), s) {
+					symbol = s
+					break
+				}
+			}
+			if symbol == "" {
+				return nil, fmt.Errorf("%v at position %v: unexpected character %q", ErrInvalidQuery, start, c)
+			}
+			i += len([]rune(symbol))
+			tokens = append(tokens, token{tokenSymbol, symbol, start})
+		}
+	}
+}
+
+func isWordStart(c rune) bool {
+	return unicode.IsLetter(c) || c == '_'
+}
+
+func isWordPart(c rune) bool {
+	return isWordStart(c) || unicode.IsDigit(c) || c == ':' || c == '-' || c == '.'
+}
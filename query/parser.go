@@ -0,0 +1,383 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/francescomari/nu/paths"
+)
+
+var keywords = map[string]bool{
+	"select": true,
+	"from":   true,
+	"where":  true,
+	"and":    true,
+	"or":     true,
+	"not":    true,
+	"like":   true,
+	"is":     true,
+	"null":   true,
+	"count":  true,
+	"sum":    true,
+}
+
+// Parse parses a query.
+func Parse(q string) (*Query, error) {
+	tokens, err := lex(q)
+	if err != nil {
+		return nil, err
+	}
+
+	p := queryParser{tokens: tokens}
+
+	query, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	return query, nil
+}
+
+type queryParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *queryParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is a word or a symbol equal to text.
+func (p *queryParser) accept(text string) bool {
+	if p.peek().is(text) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) expect(text string) error {
+	if !p.accept(text) {
+		return p.unexpected()
+	}
+	return nil
+}
+
+func (p *queryParser) unexpected() error {
+	t := p.peek()
+	return fmt.Errorf("%v at position %v: unexpected %v", ErrInvalidQuery, t.pos, t)
+}
+
+func (p *queryParser) parseQuery() (*Query, error) {
+	var (
+		query Query
+		err   error
+	)
+
+	if err := p.expect("select"); err != nil {
+		return nil, err
+	}
+
+	if query.columns, err = p.parseColumns(); err != nil {
+		return nil, err
+	}
+
+	query.from = "/"
+
+	if p.accept("from") {
+		t := p.next()
+		if t.kind != tokenString {
+			p.pos--
+			return nil, p.unexpected()
+		}
+		if _, err := paths.Parse(t.text); err != nil {
+			return nil, fmt.Errorf("%v at position %v: %v: %v", ErrInvalidQuery, t.pos, err, t.text)
+		}
+		query.from = t.text
+	}
+
+	if p.accept("where") {
+		if query.where, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek().kind != tokenEOF {
+		return nil, p.unexpected()
+	}
+
+	return &query, nil
+}
+
+func (p *queryParser) parseColumns() ([]column, error) {
+	if p.accept("*") {
+		return []column{{field: &field{kind: fieldPath, name: "path"}}}, nil
+	}
+
+	var columns []column
+
+	for {
+		c, err := p.parseColumn()
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, c)
+		if !p.accept(",") {
+			return columns, nil
+		}
+	}
+}
+
+func (p *queryParser) parseColumn() (column, error) {
+	var aggregate aggregateKind
+
+	switch {
+	case p.peek().is("count"):
+		aggregate = aggregateCount
+	case p.peek().is("sum"):
+		aggregate = aggregateSum
+	default:
+		f, err := p.parseField()
+		if err != nil {
+			return column{}, err
+		}
+		return column{field: &f}, nil
+	}
+
+	p.next()
+
+	if err := p.expect("("); err != nil {
+		return column{}, err
+	}
+
+	c := column{aggregate: aggregate}
+
+	if aggregate != aggregateCount || !p.accept("*") {
+		f, err := p.parseField()
+		if err != nil {
+			return column{}, err
+		}
+		c.field = &f
+	}
+
+	if err := p.expect(")"); err != nil {
+		return column{}, err
+	}
+
+	return c, nil
+}
+
+func (p *queryParser) parseField() (field, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenName:
+		return field{kind: fieldProperty, name: t.text}, nil
+	case tokenWord:
+		if kind, ok := pseudoFields[strings.ToLower(t.text)]; ok {
+			return field{kind: kind, name: strings.ToLower(t.text)}, nil
+		}
+		if !keywords[strings.ToLower(t.text)] {
+			return field{kind: fieldProperty, name: t.text}, nil
+		}
+	}
+
+	p.pos--
+	return field{}, p.unexpected()
+}
+
+func (p *queryParser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseNot() (expr, error) {
+	if p.accept("not") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{operand}, nil
+	}
+
+	if p.accept("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *queryParser) parseComparison() (expr, error) {
+	f, err := p.parseField()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.accept("is") {
+		not := p.accept("not")
+		if err := p.expect("null"); err != nil {
+			return nil, err
+		}
+		if not {
+			return notExpr{nullExpr{f}}, nil
+		}
+		return nullExpr{f}, nil
+	}
+
+	not := p.accept("not")
+
+	if p.accept("like") {
+		t := p.next()
+		if t.kind != tokenString {
+			p.pos--
+			return nil, p.unexpected()
+		}
+		re, err := likeToRegexp(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("%v at position %v: %v", ErrInvalidQuery, t.pos, err)
+		}
+		e := likeExpr{field: f, pattern: t.text, re: re}
+		if not {
+			return notExpr{e}, nil
+		}
+		return e, nil
+	}
+
+	if not {
+		return nil, p.unexpected()
+	}
+
+	op := p.next()
+	if op.kind != tokenSymbol {
+		p.pos--
+		return nil, p.unexpected()
+	}
+
+	switch op.text {
+	case "=", "!=", "<>", "<", "<=", ">", ">=":
+	default:
+		p.pos--
+		return nil, p.unexpected()
+	}
+
+	l, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+
+	return compareExpr{field: f, op: op.text, literal: l}, nil
+}
+
+func (p *queryParser) parseLiteral() (literal, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenString:
+		return literal{text: t.text}, nil
+	case tokenNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return literal{}, fmt.Errorf("%v at position %v: invalid number %v", ErrInvalidQuery, t.pos, t.text)
+		}
+		return literal{text: t.text, number: n, isNumber: true}, nil
+	}
+
+	p.pos--
+	return literal{}, p.unexpected()
+}
+
+// likeToRegexp converts a LIKE pattern to a regular expression matching whole
+// strings. A backslash escapes the following character.
+func likeToRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+
+	b.WriteString("(?s)^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
+
+// likePrefix returns the literal prefix of a LIKE pattern, before the first
+// wildcard.
+func likePrefix(pattern string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '%', '_':
+			return b.String()
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteByte(pattern[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,107 @@
+package query
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query   string
+		columns string
+		where   string
+	}{
+		{"select *", "path", ""},
+		{"SELECT path, [jcr:title], name FROM '/content'", "path, jcr:title, name", ""},
+		{"select [name], [select], depth", "[name], [select], depth", ""},
+		{"select count(*), count(jcr:title), sum(size)", "count(*), count(jcr:title), sum(size)", ""},
+		{"select * where a = 'x' or b = 'y' and c = 'z'", "path", "(a = 'x' OR (b = 'y' AND c = 'z'))"},
+		{"select * where (a = 'x' or b = 'y') and c = 'z'", "path", "((a = 'x' OR b = 'y') AND c = 'z')"},
+		{"select * where not a = 'it''s'", "path", "NOT a = 'it''s'"},
+		{"select * where a is null and b is not null", "path", "(a IS NULL AND NOT b IS NULL)"},
+		{"select * where path like '/a/%' and name not like 'b_'", "path", "(path LIKE '/a/%' AND NOT name LIKE 'b_')"},
+		{"select * where depth >= 2 and [size] <> -1.5", "path", "(depth >= 2 AND [size] <> -1.5)"},
+	}
+
+	for _, tt := range tests {
+		q, err := Parse(tt.query)
+		if err != nil {
+			t.Errorf("query %q: unexpected error: %v\n", tt.query, err)
+			continue
+		}
+		var columns []string
+		for _, c := range q.columns {
+			columns = append(columns, c.String())
+		}
+		if s := strings.Join(columns, ", "); s != tt.columns {
+			t.Errorf("query %q: expected columns %v, got %v\n", tt.query, tt.columns, s)
+		}
+		var where string
+		if q.where != nil {
+			where = q.where.String()
+		}
+		if where != tt.where {
+			t.Errorf("query %q: expected condition %v, got %v\n", tt.query, tt.where, where)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"select",
+		"select * from content",
+		"select * from 'content'",
+		"select * where",
+		"select * where a",
+		"select * where a = b",
+		"select * where a not = 'x'",
+		"select * where a is 'x'",
+		"select * where (a = 'x'",
+		"select * where a = 'x",
+		"select [",
+		"select []",
+		"select count(",
+		"select sum(*)",
+		"select a, where",
+		"select a b",
+		"select a # b",
+	}
+
+	for _, query := range tests {
+		if _, err := Parse(query); err == nil {
+			t.Errorf("query %q: expected error, got nil\n", query)
+		}
+	}
+}
+
+func TestLikeToRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		value   string
+		match   bool
+	}{
+		{"abc", "abc", true},
+		{"a%", "abc", true},
+		{"a%", "bac", false},
+		{"a_c", "abc", true},
+		{"a_c", "abbc", false},
+		{"%.txt", "a.txt", true},
+		{"%.txt", "abtxt", false},
+		{`a\%`, "a%", true},
+		{`a\%`, "ab", false},
+		{"ö_", "öx", true},
+		{"%", "a\nb", true},
+	}
+
+	for _, tt := range tests {
+		re, err := likeToRegexp(tt.pattern)
+		if err != nil {
+			t.Errorf("pattern %q: unexpected error: %v\n", tt.pattern, err)
+			continue
+		}
+		if match := re.MatchString(tt.value); match != tt.match {
+			t.Errorf("pattern %q, value %q: expected %v, got %v\n", tt.pattern, tt.value, tt.match, match)
+		}
+	}
+}
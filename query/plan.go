@@ -0,0 +1,198 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/francescomari/nu/paths"
+)
+
+// Plan describes how a query is evaluated over a stream of commands. The
+// planner narrows the scanned subtree and the scanned depth using the FROM
+// clause and the path and depth comparisons that must hold for every row, and
+// determines which properties must be retained for every node.
+type Plan struct {
+	query *Query
+	// root is the path of the scanned subtree.
+	root paths.Path
+	// maxDepth is the maximum depth of the rows, or -1 if unbounded.
+	maxDepth int
+	// empty is true if the query can't match any row.
+	empty bool
+	// aggregate is true if the columns are aggregates.
+	aggregate bool
+	// properties are the names of the properties to retain.
+	properties map[string]bool
+}
+
+// NewPlan creates a plan for a query.
+func NewPlan(q *Query) (*Plan, error) {
+	root, err := paths.Parse(q.from)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", ErrInvalidQuery, err)
+	}
+
+	p := Plan{
+		query:      q,
+		root:       root,
+		maxDepth:   -1,
+		properties: make(map[string]bool),
+	}
+
+	for i, c := range q.columns {
+		if i > 0 && (c.aggregate != aggregateNone) != p.aggregate {
+			return nil, fmt.Errorf("%v: fields and aggregates can't be mixed", ErrInvalidQuery)
+		}
+		p.aggregate = c.aggregate != aggregateNone
+		if c.field != nil {
+			p.retain(*c.field)
+		}
+	}
+
+	if q.where != nil {
+		p.retainExpr(q.where)
+		for _, e := range conjuncts(q.where) {
+			p.narrow(e)
+		}
+	}
+
+	return &p, nil
+}
+
+func (p *Plan) retain(f field) {
+	if f.kind == fieldProperty {
+		p.properties[f.name] = true
+	}
+}
+
+func (p *Plan) retainExpr(e expr) {
+	switch e := e.(type) {
+	case andExpr:
+		p.retainExpr(e.left)
+		p.retainExpr(e.right)
+	case orExpr:
+		p.retainExpr(e.left)
+		p.retainExpr(e.right)
+	case notExpr:
+		p.retainExpr(e.operand)
+	case compareExpr:
+		p.retain(e.field)
+	case likeExpr:
+		p.retain(e.field)
+	case nullExpr:
+		p.retain(e.field)
+	}
+}
+
+// conjuncts returns the expressions that must all be true for e to be true.
+func conjuncts(e expr) []expr {
+	if and, ok := e.(andExpr); ok {
+		return append(conjuncts(and.left), conjuncts(and.right)...)
+	}
+	return []expr{e}
+}
+
+// narrow narrows the scanned subtree or depth using an expression that must
+// be true for every row.
+func (p *Plan) narrow(e expr) {
+	switch e := e.(type) {
+	case compareExpr:
+		switch {
+		case e.field.kind == fieldPath && e.op == "=" && !e.literal.isNumber:
+			path, err := paths.Parse(e.literal.text)
+			if err != nil {
+				p.empty = true
+				return
+			}
+			p.narrowRoot(path)
+			p.narrowDepth(path.Depth())
+		case e.field.kind == fieldDepth && e.literal.isNumber:
+			switch e.op {
+			case "=", "<=":
+				p.narrowDepth(int(math.Floor(e.literal.number)))
+			case "<":
+				p.narrowDepth(int(math.Ceil(e.literal.number)) - 1)
+			}
+		}
+	case likeExpr:
+		if e.field.kind != fieldPath {
+			return
+		}
+		prefix := likePrefix(e.pattern)
+		if !strings.HasPrefix(prefix, "/") {
+			return
+		}
+		// Only the components before the last slash are complete.
+		path, err := paths.Parse(prefix[:strings.LastIndex(prefix, "/")+1])
+		if err != nil {
+			return
+		}
+		p.narrowRoot(path)
+	}
+}
+
+func (p *Plan) narrowRoot(path paths.Path) {
+	switch {
+	case p.root.Contains(path):
+		p.root = path
+	case path.Contains(p.root):
+	default:
+		p.empty = true
+	}
+}
+
+func (p *Plan) narrowDepth(depth int) {
+	if depth < 0 {
+		p.empty = true
+	}
+	if p.maxDepth < 0 || depth < p.maxDepth {
+		p.maxDepth = depth
+	}
+}
+
+// Columns returns the names of the columns of the rows produced by the plan.
+func (p *Plan) Columns() []string {
+	names := make([]string, len(p.query.columns))
+	for i, c := range p.query.columns {
+		names[i] = c.String()
+	}
+	return names
+}
+
+// String describes the plan.
+func (p *Plan) String() string {
+	var b strings.Builder
+
+	if p.empty {
+		b.WriteString("scan nothing\n")
+	} else {
+		fmt.Fprintf(&b, "scan subtree %v", p.root)
+		if p.maxDepth >= 0 {
+			fmt.Fprintf(&b, " up to depth %v", p.maxDepth)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(p.properties) > 0 {
+		names := make([]string, 0, len(p.properties))
+		for name := range p.properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(&b, "retain properties %v\n", strings.Join(names, ", "))
+	}
+
+	if p.query.where != nil {
+		fmt.Fprintf(&b, "filter %v\n", p.query.where)
+	}
+
+	if p.aggregate {
+		fmt.Fprintf(&b, "aggregate %v\n", strings.Join(p.Columns(), ", "))
+	} else {
+		fmt.Fprintf(&b, "project %v\n", strings.Join(p.Columns(), ", "))
+	}
+
+	return b.String()
+}
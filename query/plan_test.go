@@ -0,0 +1,65 @@
+package query
+
+import "testing"
+
+func TestNewPlan(t *testing.T) {
+	tests := []struct {
+		query string
+		plan  string
+	}{
+		{
+			"select *",
+			"scan subtree /\nproject path\n",
+		},
+		{
+			"select path, jcr:title from '/content' where [jcr:primaryType] = 'cq:Page'",
+			"scan subtree /content\nretain properties jcr:primaryType, jcr:title\nfilter jcr:primaryType = 'cq:Page'\nproject path, jcr:title\n",
+		},
+		{
+			"select count(*) from '/content' where path like '/content/site/%' and depth < 4",
+			"scan subtree /content/site up to depth 3\nfilter (path LIKE '/content/site/%' AND depth < 4)\naggregate count(*)\n",
+		},
+		{
+			"select * where path = '/a/b' or depth = 1",
+			"scan subtree /\nfilter (path = '/a/b' OR depth = 1)\nproject path\n",
+		},
+		{
+			"select * where path = '/a/b'",
+			"scan subtree /a/b up to depth 2\nfilter path = '/a/b'\nproject path\n",
+		},
+		{
+			"select * from '/a' where path like '/b%'",
+			"scan subtree /a\nfilter path LIKE '/b%'\nproject path\n",
+		},
+		{
+			"select * from '/a' where path like '/b/%'",
+			"scan nothing\nfilter path LIKE '/b/%'\nproject path\n",
+		},
+	}
+
+	for _, tt := range tests {
+		q, err := Parse(tt.query)
+		if err != nil {
+			t.Errorf("query %q: unexpected error: %v\n", tt.query, err)
+			continue
+		}
+		p, err := NewPlan(q)
+		if err != nil {
+			t.Errorf("query %q: unexpected error: %v\n", tt.query, err)
+			continue
+		}
+		if s := p.String(); s != tt.plan {
+			t.Errorf("query %q: expected plan %q, got %q\n", tt.query, tt.plan, s)
+		}
+	}
+}
+
+func TestNewPlanInvalid(t *testing.T) {
+	q, err := Parse("select path, count(*)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	if _, err := NewPlan(q); err == nil {
+		t.Errorf("expected error, got nil\n")
+	}
+}
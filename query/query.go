@@ -0,0 +1,249 @@
+// Package query implements a small SQL-like query language over exports.
+//
+// A query has the following form:
+//
+//	SELECT columns [FROM 'path'] [WHERE condition]
+//
+// Every node in the subtree at path, or in the whole export if FROM is
+// omitted, is a row. The columns can be `*`, which selects the path of the
+// node, a list of fields, or a list of aggregates. A field is either one of
+// the pseudo-fields `path`, `name`, `depth`, and `size`, or the name of a
+// property. Names of properties can be enclosed in square brackets, like
+// `[jcr:title]`, which is required if the name is a keyword, the name of a
+// pseudo-field, or contains unusual characters. The aggregates are `count(*)`,
+// `count(field)`, and `sum(field)`. Fields and aggregates can't be mixed.
+//
+// The condition combines comparisons with AND, OR, NOT, and parentheses. A
+// comparison has one of the following forms:
+//
+//	field op literal
+//	field [NOT] LIKE 'pattern'
+//	field IS [NOT] NULL
+//
+// where op is one of `=`, `!=`, `<>`, `<`, `<=`, `>`, and `>=`, and literal is
+// a string enclosed in single quotes or a number. A comparison with a number
+// is numeric. A comparison with a multi-valued property is true if it is true
+// for any of its values. Binary values never match a comparison. In a LIKE
+// pattern, `%` matches any sequence of characters and `_` matches a single
+// character.
+package query
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidQuery is returned when a query can't be parsed or planned.
+	ErrInvalidQuery = errors.New("invalid query")
+)
+
+// Query is a parsed query.
+type Query struct {
+	columns []column
+	from    string
+	where   expr
+}
+
+type fieldKind int
+
+const (
+	fieldPath fieldKind = iota
+	fieldName
+	fieldDepth
+	fieldSize
+	fieldProperty
+)
+
+var pseudoFields = map[string]fieldKind{
+	"path":  fieldPath,
+	"name":  fieldName,
+	"depth": fieldDepth,
+	"size":  fieldSize,
+}
+
+// field is a pseudo-field of a node or one of its properties.
+type field struct {
+	kind fieldKind
+	name string
+}
+
+func (f field) String() string {
+	if f.kind == fieldProperty {
+		if _, ok := pseudoFields[strings.ToLower(f.name)]; ok || keywords[strings.ToLower(f.name)] {
+			return "[" + f.name + "]"
+		}
+	}
+	return f.name
+}
+
+type aggregateKind int
+
+const (
+	aggregateNone aggregateKind = iota
+	aggregateCount
+	aggregateSum
+)
+
+type column struct {
+	aggregate aggregateKind
+	// field is the projected or aggregated field. field is nil for
+	// `count(*)`.
+	field *field
+}
+
+func (c column) String() string {
+	switch c.aggregate {
+	case aggregateCount:
+		if c.field == nil {
+			return "count(*)"
+		}
+		return "count(" + c.field.String() + ")"
+	case aggregateSum:
+		return "sum(" + c.field.String() + ")"
+	default:
+		return c.field.String()
+	}
+}
+
+type expr interface {
+	eval(r *row) bool
+	String() string
+}
+
+type andExpr struct {
+	left, right expr
+}
+
+func (e andExpr) eval(r *row) bool {
+	return e.left.eval(r) && e.right.eval(r)
+}
+
+func (e andExpr) String() string {
+	return "(" + e.left.String() + " AND " + e.right.String() + ")"
+}
+
+type orExpr struct {
+	left, right expr
+}
+
+func (e orExpr) eval(r *row) bool {
+	return e.left.eval(r) || e.right.eval(r)
+}
+
+func (e orExpr) String() string {
+	return "(" + e.left.String() + " OR " + e.right.String() + ")"
+}
+
+type notExpr struct {
+	operand expr
+}
+
+func (e notExpr) eval(r *row) bool {
+	return !e.operand.eval(r)
+}
+
+func (e notExpr) String() string {
+	return "NOT " + e.operand.String()
+}
+
+type literal struct {
+	text     string
+	number   float64
+	isNumber bool
+}
+
+func (l literal) String() string {
+	if l.isNumber {
+		return l.text
+	}
+	return "'" + strings.Replace(l.text, "'", "''", -1) + "'"
+}
+
+type compareExpr struct {
+	field   field
+	op      string
+	literal literal
+}
+
+func (e compareExpr) eval(r *row) bool {
+	for _, v := range r.values(e.field) {
+		if e.compare(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e compareExpr) compare(value string) bool {
+	var c int
+
+	if e.literal.isNumber {
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		switch {
+		case n < e.literal.number:
+			c = -1
+		case n > e.literal.number:
+			c = 1
+		}
+	} else {
+		c = strings.Compare(value, e.literal.text)
+	}
+
+	switch e.op {
+	case "=":
+		return c == 0
+	case "!=", "<>":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	default:
+		return false
+	}
+}
+
+func (e compareExpr) String() string {
+	return e.field.String() + " " + e.op + " " + e.literal.String()
+}
+
+type likeExpr struct {
+	field   field
+	pattern string
+	re      *regexp.Regexp
+}
+
+func (e likeExpr) eval(r *row) bool {
+	for _, v := range r.values(e.field) {
+		if e.re.MatchString(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e likeExpr) String() string {
+	return e.field.String() + " LIKE " + literal{text: e.pattern}.String()
+}
+
+type nullExpr struct {
+	field field
+}
+
+func (e nullExpr) eval(r *row) bool {
+	return r.isNull(e.field)
+}
+
+func (e nullExpr) String() string {
+	return e.field.String() + " IS NULL"
+}
@@ -0,0 +1,240 @@
+package schema
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/paths"
+)
+
+// Violation describes a node or a property violating a model, or an error if
+// the stream of commands can't be read.
+type Violation struct {
+	// Path is the path of the node or the property.
+	Path string
+	// Line is the line where the node or the property is defined, or zero if
+	// the line is unknown.
+	Line int
+	// Message describes the violation.
+	Message string
+
+	Err error
+}
+
+// Check reads a stream of commands and emits a violation for every node or
+// property not conforming to the type of its node in a model. The type of a
+// node is the value of its PrimaryTypeProperty. Nodes without a primary type,
+// or whose primary type is not declared in the model, are not checked. If an
+// error command is read, Check emits it as a violation and stops.
+func Check(model *Model, commands <-chan parser.LineCmd) <-chan Violation {
+	results := make(chan Violation)
+
+	go func() {
+		defer close(results)
+
+		c := checker{model: model, results: results}
+
+		if err := c.parse(commands); err != nil {
+			results <- *err
+		}
+	}()
+
+	return results
+}
+
+type checker struct {
+	model   *Model
+	results chan<- Violation
+}
+
+type checkedNode struct {
+	path        string
+	line        int
+	primaryType string
+	typ         *NodeType
+	// resolved is true if the properties of the node have been read and typ
+	// has been resolved.
+	resolved bool
+	// pending are the properties read before the type is resolved.
+	pending []checkedProperty
+	names   map[string]bool
+	// children is the number of children read so far for every name.
+	children map[string]int
+}
+
+type checkedProperty struct {
+	name   string
+	typ    string
+	values int
+	line   int
+}
+
+func (c *checker) parse(commands <-chan parser.LineCmd) *Violation {
+	for command := range commands {
+		switch cmd := command.Cmd.(type) {
+		case parser.R:
+			if err := c.parseNode("/", command.Line, commands); err != nil {
+				return err
+			}
+		case parser.Err:
+			return &Violation{Err: cmd.Err, Line: cmd.Line}
+		default:
+			return &Violation{Err: fmt.Errorf("unexpected command %T", cmd), Line: command.Line}
+		}
+	}
+	return nil
+}
+
+func (c *checker) parseNode(p string, line int, commands <-chan parser.LineCmd) *Violation {
+	n := checkedNode{
+		path:     p,
+		line:     line,
+		names:    make(map[string]bool),
+		children: make(map[string]int),
+	}
+
+	for command := range commands {
+		switch cmd := command.Cmd.(type) {
+		case parser.C:
+			c.resolve(&n)
+			n.children[cmd.Name]++
+			child := paths.Join(p, paths.Segment{Name: cmd.Name, Index: n.children[cmd.Name]}.String())
+			if n.typ != nil && !isAllowedChild(n.typ, cmd.Name) {
+				c.report(child, command.Line, "child %v is not allowed by type %v", cmd.Name, n.primaryType)
+			}
+			if err := c.parseNode(child, command.Line, commands); err != nil {
+				return err
+			}
+		case parser.P:
+			property, err := c.parseProperty(&n, cmd, command.Line, commands)
+			if err != nil {
+				return err
+			}
+			n.names[cmd.Name] = true
+			if n.resolved {
+				c.checkProperty(&n, property)
+			} else {
+				n.pending = append(n.pending, property)
+			}
+		case parser.Up:
+			c.resolve(&n)
+			c.checkRequired(&n)
+			return nil
+		case parser.Err:
+			return &Violation{Err: cmd.Err, Line: cmd.Line}
+		default:
+			return &Violation{Err: fmt.Errorf("unexpected command %T", cmd), Line: command.Line}
+		}
+	}
+	return nil
+}
+
+func (c *checker) parseProperty(n *checkedNode, p parser.P, line int, commands <-chan parser.LineCmd) (checkedProperty, *Violation) {
+	property := checkedProperty{name: p.Name, typ: p.Type, line: line}
+
+	for command := range commands {
+		switch cmd := command.Cmd.(type) {
+		case parser.V:
+			property.values++
+			if p.Name == PrimaryTypeProperty {
+				n.primaryType = cmd.Data
+			}
+		case parser.X:
+			property.values++
+		case parser.Up:
+			return property, nil
+		case parser.Err:
+			return property, &Violation{Err: cmd.Err, Line: cmd.Line}
+		default:
+			return property, &Violation{Err: fmt.Errorf("unexpected command %T", cmd), Line: command.Line}
+		}
+	}
+	return property, nil
+}
+
+// resolve resolves the type of a node once its properties have been read, and
+// checks the properties read so far.
+func (c *checker) resolve(n *checkedNode) {
+	if n.resolved {
+		return
+	}
+
+	n.resolved = true
+	n.typ = c.model.Types[n.primaryType]
+
+	for _, p := range n.pending {
+		c.checkProperty(n, p)
+	}
+
+	n.pending = nil
+}
+
+func (c *checker) checkProperty(n *checkedNode, p checkedProperty) {
+	if n.typ == nil {
+		return
+	}
+
+	propertyPath := paths.Join(n.path, paths.Escape(p.name))
+
+	d, ok := n.typ.Properties[p.name]
+	if !ok {
+		d, ok = n.typ.Properties[Residual]
+	}
+	if !ok {
+		c.report(propertyPath, p.line, "property %v is not declared by type %v", p.name, n.primaryType)
+		return
+	}
+
+	if len(d.Types) > 0 && !isAllowedType(d, p.typ) {
+		c.report(propertyPath, p.line, "property %v has type %v, expected %v", p.name, p.typ, strings.Join(d.Types, " or "))
+	}
+
+	if d.Multiple != nil && !*d.Multiple && p.values != 1 {
+		c.report(propertyPath, p.line, "property %v has %v values, expected a single value", p.name, p.values)
+	}
+}
+
+func (c *checker) checkRequired(n *checkedNode) {
+	if n.typ == nil {
+		return
+	}
+
+	var missing []string
+
+	for name, d := range n.typ.Properties {
+		if d.Required && !n.names[name] {
+			missing = append(missing, name)
+		}
+	}
+
+	sort.Strings(missing)
+
+	for _, name := range missing {
+		c.report(n.path, n.line, "required property %v is missing", name)
+	}
+}
+
+func (c *checker) report(p string, line int, format string, args ...interface{}) {
+	c.results <- Violation{Path: p, Line: line, Message: fmt.Sprintf(format, args...)}
+}
+
+func isAllowedType(d *PropertyDefinition, typ string) bool {
+	for _, t := range d.Types {
+		if strings.EqualFold(t, typ) {
+			return true
+		}
+	}
+	return false
+}
+
+func isAllowedChild(t *NodeType, name string) bool {
+	for _, pattern := range t.Children {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
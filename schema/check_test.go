@@ -0,0 +1,97 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+func TestCheck(t *testing.T) {
+	model, err := ReadModel(strings.NewReader(`
+types:
+  cq:Page:
+    properties:
+      jcr:primaryType: {types: [Name], required: true}
+      jcr:title: {types: [String], required: true, multiple: false}
+      tags: {types: [String], multiple: true}
+    children: [jcr:content, "*-page"]
+  cq:PageContent:
+    properties:
+      "*": {}
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	export := strings.Join([]string{
+		"r",
+		"c a",
+		"p Name jcr:primaryType",
+		"v cq:Page",
+		"^",
+		"p String jcr:title",
+		"v A",
+		"v B",
+		"^",
+		"p Long tags",
+		"v 1",
+		"^",
+		"p String other",
+		"^",
+		"c jcr:content",
+		"p Name jcr:primaryType",
+		"v cq:PageContent",
+		"^",
+		"p String anything",
+		"^",
+		"^",
+		"c b-page",
+		"p Name jcr:primaryType",
+		"v cq:Page",
+		"^",
+		"^",
+		"c images",
+		"^",
+		"^",
+		"c untyped",
+		"c whatever",
+		"^",
+		"^",
+		"^",
+	}, "\n")
+
+	var violations []Violation
+
+	for v := range Check(model, parser.ParseLines(strings.NewReader(export))) {
+		if v.Err != nil {
+			t.Fatalf("error at line %v: %v\n", v.Line, v.Err)
+		}
+		violations = append(violations, v)
+	}
+
+	expected := []Violation{
+		{Path: "/a/jcr:title", Line: 6, Message: "property jcr:title has 2 values, expected a single value"},
+		{Path: "/a/tags", Line: 10, Message: "property tags has type Long, expected String"},
+		{Path: "/a/other", Line: 13, Message: "property other is not declared by type cq:Page"},
+		{Path: "/a/b-page", Line: 22, Message: "required property jcr:title is missing"},
+		{Path: "/a/images", Line: 27, Message: "child images is not allowed by type cq:Page"},
+	}
+
+	if !reflect.DeepEqual(violations, expected) {
+		t.Errorf("expected %+v, got %+v\n", expected, violations)
+	}
+}
+
+func TestCheckError(t *testing.T) {
+	var violations []Violation
+
+	for v := range Check(&Model{}, parser.ParseLines(strings.NewReader("r\nc a\nfoo\n"))) {
+		violations = append(violations, v)
+	}
+
+	if len(violations) != 1 || violations[0].Err == nil || violations[0].Line != 3 {
+		t.Errorf("expected an error at line 3, got %+v\n", violations)
+	}
+}
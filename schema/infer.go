@@ -0,0 +1,284 @@
+package schema
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/paths"
+)
+
+const (
+	// PrimaryTypeProperty is the name of the property holding the primary
+	// type of a node.
+	PrimaryTypeProperty = "jcr:primaryType"
+	// MaxChildNames is the maximum number of distinct child names tracked
+	// for every group by Infer.
+	MaxChildNames = 1000
+)
+
+// Inferred is the content model inferred from an export.
+type Inferred struct {
+	// Groups are the groups of nodes, sorted by name.
+	Groups []*Group `json:"groups"`
+}
+
+// Group describes a group of nodes sharing the same primary type, or whose
+// paths match the same pattern.
+type Group struct {
+	// Name is the primary type or the pattern shared by the nodes. Name is
+	// empty for the nodes without a primary type.
+	Name string `json:"name"`
+	// Nodes is the number of nodes in the group.
+	Nodes int `json:"nodes"`
+	// Properties describe the properties of the nodes, sorted by name.
+	Properties []*PropertyInfo `json:"properties"`
+	// Children describe the names of the children of the nodes, sorted by
+	// decreasing count.
+	Children []*ChildInfo `json:"children"`
+	// OtherChildren is the number of children whose name is not tracked,
+	// because the group already tracks MaxChildNames distinct names.
+	OtherChildren int `json:"otherChildren,omitempty"`
+
+	properties map[string]*PropertyInfo
+	children   map[string]*ChildInfo
+}
+
+// PropertyInfo describes a property in a group.
+type PropertyInfo struct {
+	// Name is the name of the property.
+	Name string `json:"name"`
+	// Count is the number of nodes in the group having the property.
+	Count int `json:"count"`
+	// Types is the number of occurrences of the property by type.
+	Types map[string]int `json:"types"`
+	// Multiple is true if any occurrence of the property doesn't have exactly
+	// one value.
+	Multiple bool `json:"multiple"`
+}
+
+// ChildInfo describes a child name in a group.
+type ChildInfo struct {
+	// Name is the name of the child.
+	Name string `json:"name"`
+	// Count is the number of nodes in the group having a child with this
+	// name.
+	Count int `json:"count"`
+}
+
+// InferOptions controls how Infer groups the nodes.
+type InferOptions struct {
+	// Patterns, if not empty, are glob patterns used to group the nodes
+	// instead of their primary type. A node belongs to the group of the first
+	// pattern matching its path. Nodes not matching any pattern are ignored.
+	Patterns []string
+}
+
+// Infer reads a stream of commands and infers a content model from it. Infer
+// either returns a non-nil Inferred or an error.
+func Infer(commands <-chan parser.Cmd, options InferOptions) (*Inferred, error) {
+	for _, pattern := range options.Patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %v: %v", pattern, err)
+		}
+	}
+
+	i := inferrer{
+		options: options,
+		groups:  make(map[string]*Group),
+	}
+
+	if err := i.parse(commands); err != nil {
+		return nil, err
+	}
+
+	return i.result(), nil
+}
+
+type inferrer struct {
+	options InferOptions
+	groups  map[string]*Group
+}
+
+// node is what is observed about a node while reading its subtree.
+type node struct {
+	path        string
+	primaryType string
+	properties  map[string]parser.P
+	multiple    map[string]bool
+	children    map[string]bool
+}
+
+func (i *inferrer) parse(commands <-chan parser.Cmd) error {
+	for command := range commands {
+		switch cmd := command.(type) {
+		case parser.R:
+			if err := i.parseNode("/", commands); err != nil {
+				return err
+			}
+		case parser.Err:
+			return onError(cmd)
+		default:
+			return onUnexpected(cmd)
+		}
+	}
+	return nil
+}
+
+func (i *inferrer) parseNode(p string, commands <-chan parser.Cmd) error {
+	n := node{
+		path:       p,
+		properties: make(map[string]parser.P),
+		multiple:   make(map[string]bool),
+		children:   make(map[string]bool),
+	}
+
+	// siblings is the number of children read so far for every name.
+	siblings := make(map[string]int)
+
+	for command := range commands {
+		switch cmd := command.(type) {
+		case parser.C:
+			n.children[cmd.Name] = true
+			siblings[cmd.Name]++
+			child := paths.Segment{Name: cmd.Name, Index: siblings[cmd.Name]}
+			if err := i.parseNode(paths.Join(p, child.String()), commands); err != nil {
+				return err
+			}
+		case parser.P:
+			if err := i.parseProperty(&n, cmd, commands); err != nil {
+				return err
+			}
+		case parser.Up:
+			i.add(&n)
+			return nil
+		case parser.Err:
+			return onError(cmd)
+		default:
+			return onUnexpected(cmd)
+		}
+	}
+	return nil
+}
+
+func (i *inferrer) parseProperty(n *node, p parser.P, commands <-chan parser.Cmd) error {
+	var values int
+
+	for command := range commands {
+		switch cmd := command.(type) {
+		case parser.V:
+			values++
+			if p.Name == PrimaryTypeProperty {
+				n.primaryType = cmd.Data
+			}
+		case parser.X:
+			values++
+		case parser.Up:
+			n.properties[p.Name] = p
+			n.multiple[p.Name] = values != 1
+			return nil
+		case parser.Err:
+			return onError(cmd)
+		default:
+			return onUnexpected(cmd)
+		}
+	}
+	return nil
+}
+
+func (i *inferrer) add(n *node) {
+	name, ok := i.groupName(n)
+	if !ok {
+		return
+	}
+
+	g := i.groups[name]
+	if g == nil {
+		g = &Group{
+			Name:       name,
+			properties: make(map[string]*PropertyInfo),
+			children:   make(map[string]*ChildInfo),
+		}
+		i.groups[name] = g
+	}
+
+	g.Nodes++
+
+	for name, p := range n.properties {
+		info := g.properties[name]
+		if info == nil {
+			info = &PropertyInfo{Name: name, Types: make(map[string]int)}
+			g.properties[name] = info
+		}
+		info.Count++
+		info.Types[p.Type]++
+		info.Multiple = info.Multiple || n.multiple[name]
+	}
+
+	for name := range n.children {
+		info := g.children[name]
+		if info == nil {
+			if len(g.children) >= MaxChildNames {
+				g.OtherChildren++
+				continue
+			}
+			info = &ChildInfo{Name: name}
+			g.children[name] = info
+		}
+		info.Count++
+	}
+}
+
+func (i *inferrer) groupName(n *node) (string, bool) {
+	if len(i.options.Patterns) == 0 {
+		return n.primaryType, true
+	}
+	for _, pattern := range i.options.Patterns {
+		if ok, _ := path.Match(pattern, n.path); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+func (i *inferrer) result() *Inferred {
+	var result Inferred
+
+	for _, g := range i.groups {
+		g.Properties = make([]*PropertyInfo, 0, len(g.properties))
+		for _, p := range g.properties {
+			g.Properties = append(g.Properties, p)
+		}
+		sort.Slice(g.Properties, func(a, b int) bool {
+			return g.Properties[a].Name < g.Properties[b].Name
+		})
+
+		g.Children = make([]*ChildInfo, 0, len(g.children))
+		for _, c := range g.children {
+			g.Children = append(g.Children, c)
+		}
+		sort.Slice(g.Children, func(a, b int) bool {
+			if g.Children[a].Count != g.Children[b].Count {
+				return g.Children[a].Count > g.Children[b].Count
+			}
+			return g.Children[a].Name < g.Children[b].Name
+		})
+
+		result.Groups = append(result.Groups, g)
+	}
+
+	sort.Slice(result.Groups, func(a, b int) bool {
+		return result.Groups[a].Name < result.Groups[b].Name
+	})
+
+	return &result
+}
+
+func onError(cmd parser.Err) error {
+	return fmt.Errorf("error at line %v: %v", cmd.Line, cmd.Err)
+}
+
+func onUnexpected(cmd parser.Cmd) error {
+	return fmt.Errorf("unexpected command %T", cmd)
+}
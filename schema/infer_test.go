@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+const inferExport = `
+	r
+	p Name jcr:primaryType
+	v rep:root
+	^
+	c a
+	p Name jcr:primaryType
+	v cq:Page
+	^
+	p String tags
+	v x
+	v y
+	^
+	c jcr:content
+	^
+	^
+	c b
+	p Name jcr:primaryType
+	v cq:Page
+	^
+	p String tags
+	v x
+	^
+	p Date created
+	v 2020-01-01
+	^
+	c jcr:content
+	^
+	c c
+	^
+	^
+	^
+`
+
+func TestInferByPrimaryType(t *testing.T) {
+	inferred, err := Infer(parser.Parse(strings.NewReader(inferExport)), InferOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	var names []string
+	for _, g := range inferred.Groups {
+		names = append(names, g.Name)
+	}
+	if expected := []string{"", "cq:Page", "rep:root"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected groups %v, got %v\n", expected, names)
+	}
+
+	page := inferred.Groups[1]
+
+	if page.Nodes != 2 {
+		t.Errorf("expected 2 nodes, got %v\n", page.Nodes)
+	}
+
+	expectedProperties := []*PropertyInfo{
+		{Name: "created", Count: 1, Types: map[string]int{"Date": 1}},
+		{Name: "jcr:primaryType", Count: 2, Types: map[string]int{"Name": 2}},
+		{Name: "tags", Count: 2, Types: map[string]int{"String": 2}, Multiple: true},
+	}
+	for i, p := range expectedProperties {
+		if i >= len(page.Properties) || !reflect.DeepEqual(page.Properties[i], p) {
+			t.Errorf("expected property %+v, got %+v\n", p, page.Properties)
+		}
+	}
+
+	expectedChildren := []*ChildInfo{
+		{Name: "jcr:content", Count: 2},
+		{Name: "c", Count: 1},
+	}
+	if !reflect.DeepEqual(page.Children, expectedChildren) {
+		t.Errorf("expected children %+v, got %+v\n", expectedChildren, page.Children)
+	}
+
+	if untyped := inferred.Groups[0]; untyped.Nodes != 3 {
+		t.Errorf("expected 3 nodes without primary type, got %v\n", untyped.Nodes)
+	}
+}
+
+func TestInferByPattern(t *testing.T) {
+	options := InferOptions{
+		Patterns: []string{"/*/jcr:content", "/*"},
+	}
+
+	inferred, err := Infer(parser.Parse(strings.NewReader(inferExport)), options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	if len(inferred.Groups) != 2 {
+		t.Fatalf("expected 2 groups, got %v\n", len(inferred.Groups))
+	}
+	// The root matches "/*" too.
+	if g := inferred.Groups[0]; g.Name != "/*" || g.Nodes != 3 {
+		t.Errorf("expected 3 nodes for /*, got %v for %v\n", g.Nodes, g.Name)
+	}
+	if g := inferred.Groups[1]; g.Name != "/*/jcr:content" || g.Nodes != 2 {
+		t.Errorf("expected 2 nodes for /*/jcr:content, got %v for %v\n", g.Nodes, g.Name)
+	}
+}
+
+func TestInferInvalidPattern(t *testing.T) {
+	if _, err := Infer(make(chan parser.Cmd), InferOptions{Patterns: []string{"["}}); err == nil {
+		t.Errorf("expected error, got nil\n")
+	}
+}
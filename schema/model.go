@@ -0,0 +1,105 @@
+package schema
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Residual is the name of a property definition matching every property not
+// otherwise defined, and the child name pattern matching every name.
+const Residual = "*"
+
+// Model is a content model declaring the allowed shape of the nodes of every
+// node type. A model is usually read from a YAML file like the following:
+//
+//	types:
+//	  cq:Page:
+//	    properties:
+//	      jcr:primaryType:
+//	        types: [Name]
+//	        required: true
+//	      tags:
+//	        types: [String]
+//	        multiple: true
+//	    children: [jcr:content]
+//	  nt:unstructured:
+//	    properties:
+//	      "*": {}
+//	    children: ["*"]
+//
+// Properties not declared by the type of a node are only allowed if the type
+// has a residual property definition, named `*`. Children are only allowed if
+// their name matches one of the glob patterns in the type.
+type Model struct {
+	// Types are the node types, by name.
+	Types map[string]*NodeType `yaml:"types"`
+}
+
+// NodeType declares the properties and the children allowed for the nodes of
+// a type.
+type NodeType struct {
+	// Properties are the property definitions of the type, by property name.
+	Properties map[string]*PropertyDefinition `yaml:"properties"`
+	// Children are the glob patterns matching the allowed child names.
+	Children []string `yaml:"children"`
+}
+
+// PropertyDefinition declares the allowed shape of a property.
+type PropertyDefinition struct {
+	// Types are the allowed types of the property. Types are compared
+	// case-insensitively. If Types is empty, every type is allowed.
+	Types []string `yaml:"types"`
+	// Required is true if the property must exist.
+	Required bool `yaml:"required"`
+	// Multiple, if not nil, tells if the property is multi-valued. A property
+	// that is not multi-valued must have exactly one value.
+	Multiple *bool `yaml:"multiple"`
+}
+
+// ReadModel reads a model in YAML from a io.Reader. Unknown fields are
+// rejected.
+func ReadModel(r io.Reader) (*Model, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var model Model
+
+	if err := yaml.UnmarshalStrict(data, &model); err != nil {
+		return nil, err
+	}
+
+	if err := model.validate(); err != nil {
+		return nil, err
+	}
+
+	return &model, nil
+}
+
+func (m *Model) validate() error {
+	for name, t := range m.Types {
+		if t == nil {
+			return fmt.Errorf("type %v: empty definition", name)
+		}
+		for property, d := range t.Properties {
+			if d == nil {
+				t.Properties[property] = &PropertyDefinition{}
+				continue
+			}
+			if property == Residual && d.Required {
+				return fmt.Errorf("type %v: the residual property definition can't be required", name)
+			}
+		}
+		for _, pattern := range t.Children {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("type %v: invalid child pattern %v: %v", name, pattern, err)
+			}
+		}
+	}
+	return nil
+}
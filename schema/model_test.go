@@ -0,0 +1,59 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadModel(t *testing.T) {
+	model, err := ReadModel(strings.NewReader(`
+types:
+  cq:Page:
+    properties:
+      jcr:primaryType:
+        types: [Name]
+        required: true
+      tags:
+        types: [String]
+        multiple: true
+      "*":
+    children: [jcr:content]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	page, ok := model.Types["cq:Page"]
+	if !ok {
+		t.Fatalf("expected type cq:Page, got %v\n", model.Types)
+	}
+	if d := page.Properties["jcr:primaryType"]; !d.Required || len(d.Types) != 1 || d.Types[0] != "Name" || d.Multiple != nil {
+		t.Errorf("unexpected definition for jcr:primaryType: %+v\n", d)
+	}
+	if d := page.Properties["tags"]; d.Required || d.Multiple == nil || !*d.Multiple {
+		t.Errorf("unexpected definition for tags: %+v\n", d)
+	}
+	if d := page.Properties[Residual]; d == nil {
+		t.Errorf("expected residual definition, got nil\n")
+	}
+	if len(page.Children) != 1 || page.Children[0] != "jcr:content" {
+		t.Errorf("expected children [jcr:content], got %v\n", page.Children)
+	}
+}
+
+func TestReadModelInvalid(t *testing.T) {
+	tests := []string{
+		"types: [a]",
+		"unknown: 1",
+		"types:\n  a:\n    mandatory: true\n",
+		"types:\n  a:\n",
+		"types:\n  a:\n    children: ['[']\n",
+		"types:\n  a:\n    properties:\n      '*': {required: true}\n",
+	}
+
+	for _, model := range tests {
+		if _, err := ReadModel(strings.NewReader(model)); err == nil {
+			t.Errorf("model %q: expected error, got nil\n", model)
+		}
+	}
+}
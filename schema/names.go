@@ -0,0 +1,72 @@
+package schema
+
+import (
+	"fmt"
+
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/paths"
+)
+
+// CheckNames reads a stream of commands and emits a violation for every node or
+// property whose name is not a valid JCR name, as defined by
+// paths.ValidateName. If an error command or an unexpected command is read,
+// CheckNames emits it as a violation and stops.
+func CheckNames(commands <-chan parser.LineCmd) <-chan Violation {
+	results := make(chan Violation)
+
+	go func() {
+		defer close(results)
+
+		var (
+			// current are the paths of the open nodes and property.
+			current  []string
+			siblings paths.Siblings
+		)
+
+		for command := range commands {
+			switch cmd := command.Cmd.(type) {
+			case parser.R:
+				if len(current) > 0 {
+					results <- Violation{Err: fmt.Errorf("unexpected command %T", cmd), Line: command.Line}
+					return
+				}
+				siblings.Enter("")
+				current = append(current, "/")
+			case parser.C:
+				if len(current) == 0 {
+					results <- Violation{Err: fmt.Errorf("unexpected command %T", cmd), Line: command.Line}
+					return
+				}
+				segment := paths.Segment{Name: cmd.Name, Index: siblings.Enter(cmd.Name)}
+				p := paths.Join(current[len(current)-1], segment.String())
+				if err := paths.ValidateName(cmd.Name); err != nil {
+					results <- Violation{Path: p, Line: command.Line, Message: fmt.Sprintf("node %v", err)}
+				}
+				current = append(current, p)
+			case parser.P:
+				if len(current) == 0 {
+					results <- Violation{Err: fmt.Errorf("unexpected command %T", cmd), Line: command.Line}
+					return
+				}
+				siblings.EnterProperty()
+				p := paths.Join(current[len(current)-1], paths.Escape(cmd.Name))
+				if err := paths.ValidateName(cmd.Name); err != nil {
+					results <- Violation{Path: p, Line: command.Line, Message: fmt.Sprintf("property %v", err)}
+				}
+				current = append(current, p)
+			case parser.Up:
+				if len(current) == 0 {
+					results <- Violation{Err: fmt.Errorf("unexpected command %T", cmd), Line: command.Line}
+					return
+				}
+				siblings.Leave()
+				current = current[:len(current)-1]
+			case parser.Err:
+				results <- Violation{Err: cmd.Err, Line: cmd.Line}
+				return
+			}
+		}
+	}()
+
+	return results
+}
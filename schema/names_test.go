@@ -0,0 +1,79 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+func TestCheckNames(t *testing.T) {
+	export := strings.Join([]string{
+		"r",
+		"c jcr:content",
+		"p String a|b",
+		"^",
+		"c a/b",
+		"c ..",
+		"^",
+		"^",
+		"^",
+		"c ok",
+		"p String a*",
+		"^",
+		"^",
+		"^",
+	}, "\n")
+
+	var violations []Violation
+
+	for v := range CheckNames(parser.ParseLines(strings.NewReader(export))) {
+		if v.Err != nil {
+			t.Fatalf("error at line %v: %v\n", v.Line, v.Err)
+		}
+		violations = append(violations, v)
+	}
+
+	expected := []Violation{
+		{Path: "/jcr:content/a%7Cb", Line: 3, Message: `property invalid name: local name contains '|'`},
+		{Path: "/jcr:content/a%2Fb", Line: 5, Message: `node invalid name: local name contains '/'`},
+		{Path: "/jcr:content/a%2Fb/%2E%2E", Line: 6, Message: "node invalid name: reserved local name .."},
+		{Path: "/ok/a*", Line: 11, Message: `property invalid name: local name contains '*'`},
+	}
+
+	if !reflect.DeepEqual(violations, expected) {
+		t.Errorf("expected %+v, got %+v\n", expected, violations)
+	}
+}
+
+func TestCheckNamesUnexpectedCommand(t *testing.T) {
+	for _, export := range []string{"c a\n^\n", "r\n^\n^\n", "p String a\n^\n"} {
+		var failed bool
+		for v := range CheckNames(parser.ParseLines(strings.NewReader(export))) {
+			if v.Err != nil {
+				failed = true
+			}
+		}
+		if !failed {
+			t.Errorf("export %q: expected error, got none\n", export)
+		}
+	}
+}
+
+func TestCheckNamesSameNameSiblings(t *testing.T) {
+	export := "r\nc a\n^\nc a\nc b|c\n^\n^\n^\n"
+
+	var paths []string
+
+	for v := range CheckNames(parser.ParseLines(strings.NewReader(export))) {
+		if v.Err != nil {
+			t.Fatalf("error at line %v: %v\n", v.Line, v.Err)
+		}
+		paths = append(paths, v.Path)
+	}
+
+	if len(paths) != 1 || paths[0] != "/a[2]/b%7Cc" {
+		t.Errorf("expected [/a[2]/b%%7Cc], got %v\n", paths)
+	}
+}
@@ -0,0 +1,85 @@
+package serializer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/francescomari/nu/parser"
+)
+
+// SerializeBinary serializes a stream of commands into a io.Writer using the
+// binary format understood by parser.ParseBinary. If an error command is
+// returned from the stream, or if an unexpected command is met,
+// SerializeBinary returns with a non-nil error.
+func SerializeBinary(commands <-chan parser.Cmd, w io.Writer) error {
+	s := binarySerializer{
+		w:       bufio.NewWriter(w),
+		strings: make(map[string]uint64),
+	}
+
+	s.w.WriteString(parser.BinaryMagic)
+
+	for command := range commands {
+		switch cmd := command.(type) {
+		case parser.R:
+			s.w.WriteByte(parser.BinaryOpR)
+		case parser.C:
+			s.w.WriteByte(parser.BinaryOpC)
+			s.writeRef(cmd.Name)
+		case parser.P:
+			s.w.WriteByte(parser.BinaryOpP)
+			s.writeRef(cmd.Type)
+			s.writeRef(cmd.Name)
+		case parser.V:
+			s.w.WriteByte(parser.BinaryOpV)
+			s.writeString(cmd.Data)
+		case parser.X:
+			if data, err := hex.DecodeString(cmd.Data); err == nil && hex.EncodeToString(data) == cmd.Data {
+				s.w.WriteByte(parser.BinaryOpX)
+				s.writeString(string(data))
+			} else {
+				s.w.WriteByte(parser.BinaryOpXString)
+				s.writeString(cmd.Data)
+			}
+		case parser.Up:
+			s.w.WriteByte(parser.BinaryOpUp)
+		case parser.Err:
+			return fmt.Errorf("error at line %v: %v", cmd.Line, cmd.Err)
+		default:
+			return fmt.Errorf("unrecognized command: %#v", cmd)
+		}
+	}
+
+	return s.w.Flush()
+}
+
+type binarySerializer struct {
+	w       *bufio.Writer
+	strings map[string]uint64
+	buffer  [binary.MaxVarintLen64]byte
+}
+
+func (s *binarySerializer) writeRef(v string) {
+	if ref, ok := s.strings[v]; ok {
+		s.writeUvarint(ref)
+		return
+	}
+	if len(s.strings) < parser.BinaryMaxStrings {
+		s.strings[v] = uint64(len(s.strings) + 1)
+	}
+	s.writeUvarint(0)
+	s.writeString(v)
+}
+
+func (s *binarySerializer) writeString(v string) {
+	s.writeUvarint(uint64(len(v)))
+	s.w.WriteString(v)
+}
+
+func (s *binarySerializer) writeUvarint(v uint64) {
+	n := binary.PutUvarint(s.buffer[:], v)
+	s.w.Write(s.buffer[:n])
+}
@@ -0,0 +1,76 @@
+package serializer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+func TestSerializeBinary(t *testing.T) {
+	cmds := []parser.Cmd{
+		parser.R{},
+		parser.C{Name: "a"},
+		parser.P{Type: "string", Name: "a"},
+		parser.V{Data: "a\nb"},
+		parser.Up{},
+		parser.P{Type: "string", Name: "b"},
+		parser.X{Data: "cafe"},
+		parser.X{Data: "AB"},
+		parser.Up{},
+		parser.Up{},
+		parser.C{Name: "a"},
+		parser.Up{},
+		parser.Up{},
+	}
+
+	ch := make(chan parser.Cmd)
+
+	go func() {
+		defer close(ch)
+		for _, cmd := range cmds {
+			ch <- cmd
+		}
+	}()
+
+	var w strings.Builder
+
+	if err := SerializeBinary(ch, &w); err != nil {
+		t.Fatalf("serialize: %v\n", err)
+	}
+
+	var e string
+
+	e += parser.BinaryMagic
+	e += "r"
+	e += "c\x00\x01a"
+	e += "p\x00\x06string\x01"
+	e += "v\x03a\nb"
+	e += "^"
+	e += "p\x02\x00\x01b"
+	e += "x\x02\xca\xfe"
+	e += "X\x02AB"
+	e += "^"
+	e += "^"
+	e += "c\x01"
+	e += "^"
+	e += "^"
+
+	if e != w.String() {
+		t.Fatalf("unexpected output:\n%q", w.String())
+	}
+
+	var all []parser.Cmd
+	for cmd := range parser.ParseBinary(strings.NewReader(w.String())) {
+		all = append(all, cmd)
+	}
+
+	if len(all) != len(cmds) {
+		t.Fatalf("expected %d commands, got %d\n", len(cmds), len(all))
+	}
+	for i, cmd := range cmds {
+		if cmd != all[i] {
+			t.Errorf("expected %v, got %v\n", cmd, all[i])
+		}
+	}
+}
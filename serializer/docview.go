@@ -0,0 +1,250 @@
+package serializer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/francescomari/nu/parser"
+)
+
+// ScanPrefixes reads a stream of commands and returns the namespace prefixes
+// that SerializeDocView needs to declare for the same stream, sorted. These are
+// the prefixes of the names of nodes and properties, of the values of the
+// properties of type Name and Path, and the prefix `jcr` of the root element.
+// The reserved prefix `xml` is never returned. If an error command is returned
+// from the stream, ScanPrefixes returns with a non-nil error.
+func ScanPrefixes(commands <-chan parser.Cmd) ([]string, error) {
+	var (
+		used  = map[string]bool{"jcr": true}
+		value func(string) []string
+	)
+
+	for command := range commands {
+		switch cmd := command.(type) {
+		case parser.C:
+			used[xmlPrefix(cmd.Name)] = true
+		case parser.P:
+			used[xmlPrefix(cmd.Name)] = true
+			value = valuePrefixes(cmd.Type)
+		case parser.V:
+			if value != nil {
+				for _, prefix := range value(cmd.Data) {
+					used[prefix] = true
+				}
+			}
+		case parser.Up:
+			value = nil
+		case parser.Err:
+			return nil, fmt.Errorf("error at line %v: %v", cmd.Line, cmd.Err)
+		}
+	}
+
+	delete(used, "")
+	delete(used, "xml")
+
+	prefixes := make([]string, 0, len(used))
+	for prefix := range used {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	return prefixes, nil
+}
+
+// valuePrefixes returns a function returning the prefixes used by a value of a
+// property of the specified type, or nil if the values of the type don't use
+// namespace prefixes.
+func valuePrefixes(t string) func(string) []string {
+	switch {
+	case strings.EqualFold(t, "Name"):
+		return func(v string) []string {
+			return []string{xmlPrefix(v)}
+		}
+	case strings.EqualFold(t, "Path"):
+		return func(v string) []string {
+			var prefixes []string
+			for _, c := range strings.Split(v, "/") {
+				prefixes = append(prefixes, xmlPrefix(c))
+			}
+			return prefixes
+		}
+	default:
+		return nil
+	}
+}
+
+// SerializeDocView serializes a stream of commands as a JCR Document View XML
+// document into a io.Writer. Every node is serialized as an element, and every
+// property as an attribute of the element. The name of the root node is always
+// `jcr:root`. Names are escaped as described by ISO 9075. The values of
+// multi-valued properties are separated by spaces, and the spaces inside the
+// values are escaped. The payload of binary values is Base64-encoded. The
+// namespaces, mapping prefixes to URIs, are declared on the root element, and
+// must include every prefix returned by ScanPrefixes for the same stream. The
+// document is written while the commands are read, so every property of a node
+// must precede its children. If an error command is returned from the stream,
+// if an unexpected command is met, or if a name uses an undeclared prefix,
+// SerializeDocView returns with a non-nil error.
+func SerializeDocView(commands <-chan parser.Cmd, namespaces map[string]string, w io.Writer) error {
+	s := docViewSerializer{w: bufio.NewWriter(w), namespaces: namespaces}
+
+	for command := range commands {
+		if err := s.serialize(command); err != nil {
+			return err
+		}
+	}
+
+	return s.w.Flush()
+}
+
+type docViewAttribute struct {
+	name  string
+	value string
+}
+
+type docViewSerializer struct {
+	w          *bufio.Writer
+	namespaces map[string]string
+	// names are the escaped names of the open elements.
+	names []string
+	// pending is true if the start tag of the innermost element has not been
+	// written yet.
+	pending    bool
+	attributes []docViewAttribute
+	property   *parser.P
+	values     []string
+}
+
+func (s *docViewSerializer) serialize(command parser.Cmd) error {
+	switch cmd := command.(type) {
+	case parser.R:
+		if len(s.names) > 0 {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		if err := s.checkPrefix(xmlRootName); err != nil {
+			return err
+		}
+		s.w.WriteString(xmlHeader)
+		s.open(xmlRootName)
+		prefixes := make([]string, 0, len(s.namespaces))
+		for prefix := range s.namespaces {
+			prefixes = append(prefixes, prefix)
+		}
+		sort.Strings(prefixes)
+		for _, prefix := range prefixes {
+			s.attributes = append(s.attributes, docViewAttribute{"xmlns:" + prefix, s.namespaces[prefix]})
+		}
+	case parser.C:
+		if len(s.names) == 0 || s.property != nil {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		if err := s.checkPrefix(cmd.Name); err != nil {
+			return err
+		}
+		if s.pending {
+			s.writeStartTag(false)
+		}
+		s.open(escapeXMLName(cmd.Name))
+	case parser.P:
+		if len(s.names) == 0 || s.property != nil {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		if !s.pending {
+			return fmt.Errorf("property %v follows a child node", cmd.Name)
+		}
+		if err := s.checkPrefix(cmd.Name); err != nil {
+			return err
+		}
+		s.property = &cmd
+		s.values = nil
+	case parser.V:
+		if s.property == nil {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		s.values = append(s.values, cmd.Data)
+	case parser.X:
+		if s.property == nil {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		value, err := binaryToBase64(cmd.Data)
+		if err != nil {
+			return err
+		}
+		s.values = append(s.values, value)
+	case parser.Up:
+		if s.property != nil {
+			s.addAttribute()
+			s.property = nil
+			return nil
+		}
+		if len(s.names) == 0 {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		if s.pending {
+			s.writeStartTag(true)
+		} else {
+			writeXMLIndent(s.w, len(s.names)-1)
+			s.w.WriteString("</" + s.names[len(s.names)-1] + ">\n")
+		}
+		s.names = s.names[:len(s.names)-1]
+	case parser.Err:
+		return fmt.Errorf("error at line %v: %v", cmd.Line, cmd.Err)
+	default:
+		return fmt.Errorf("unrecognized command: %#v", cmd)
+	}
+	return nil
+}
+
+func (s *docViewSerializer) checkPrefix(name string) error {
+	prefix := xmlPrefix(name)
+	if prefix == "" || prefix == "xml" {
+		return nil
+	}
+	if _, ok := s.namespaces[prefix]; !ok {
+		return fmt.Errorf("namespace prefix %v of %v is not declared", prefix, name)
+	}
+	return nil
+}
+
+func (s *docViewSerializer) open(name string) {
+	s.names = append(s.names, name)
+	s.pending = true
+	s.attributes = nil
+}
+
+func (s *docViewSerializer) addAttribute() {
+	var value string
+	if len(s.values) == 1 {
+		value = s.values[0]
+	} else {
+		escaped := make([]string, len(s.values))
+		for i, v := range s.values {
+			escaped[i] = escapeXMLSpaces(v)
+		}
+		value = strings.Join(escaped, " ")
+	}
+	s.attributes = append(s.attributes, docViewAttribute{escapeXMLName(s.property.Name), value})
+}
+
+func (s *docViewSerializer) writeStartTag(empty bool) {
+	depth := len(s.names) - 1
+	writeXMLIndent(s.w, depth)
+	s.w.WriteString("<" + s.names[depth])
+	for _, a := range s.attributes {
+		s.w.WriteString("\n")
+		writeXMLIndent(s.w, depth+2)
+		s.w.WriteString(a.name + `="`)
+		writeXMLEscaped(s.w, a.value)
+		s.w.WriteString(`"`)
+	}
+	if empty {
+		s.w.WriteString("/>\n")
+	} else {
+		s.w.WriteString(">\n")
+	}
+	s.pending = false
+	s.attributes = nil
+}
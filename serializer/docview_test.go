@@ -0,0 +1,144 @@
+package serializer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+func TestSerializeDocView(t *testing.T) {
+	ch := make(chan parser.Cmd)
+
+	go func() {
+		defer close(ch)
+		for _, cmd := range []parser.Cmd{
+			parser.R{},
+			parser.P{Type: "name", Name: "jcr:primaryType"},
+			parser.V{Data: "rep:root"},
+			parser.Up{},
+			parser.C{Name: "a b"},
+			parser.P{Type: "binary", Name: "c"},
+			parser.X{Data: "68656c6c6f"},
+			parser.Up{},
+			parser.P{Type: "string", Name: "d"},
+			parser.V{Data: "e f"},
+			parser.V{Data: "\"g\""},
+			parser.Up{},
+			parser.C{Name: "h"},
+			parser.Up{},
+			parser.Up{},
+			parser.Up{},
+		} {
+			ch <- cmd
+		}
+	}()
+
+	var w strings.Builder
+
+	if err := SerializeDocView(ch, BuiltinNamespaces, &w); err != nil {
+		t.Fatalf("serialize: %v\n", err)
+	}
+
+	var e string
+
+	e += `<?xml version="1.0" encoding="UTF-8"?>` + "\n"
+	e += `<jcr:root` + "\n"
+	e += `    xmlns:jcr="http://www.jcp.org/jcr/1.0"` + "\n"
+	e += `    xmlns:mix="http://www.jcp.org/jcr/mix/1.0"` + "\n"
+	e += `    xmlns:nt="http://www.jcp.org/jcr/nt/1.0"` + "\n"
+	e += `    xmlns:rep="internal"` + "\n"
+	e += `    jcr:primaryType="rep:root">` + "\n"
+	e += `  <a_x0020_b` + "\n"
+	e += `      c="aGVsbG8="` + "\n"
+	e += `      d="e_x0020_f &#34;g&#34;">` + "\n"
+	e += `    <h/>` + "\n"
+	e += `  </a_x0020_b>` + "\n"
+	e += `</jcr:root>` + "\n"
+
+	if e != w.String() {
+		t.Fatalf("unexpected output:\n%v", w.String())
+	}
+}
+
+func TestSerializeDocViewPropertyAfterNode(t *testing.T) {
+	ch := make(chan parser.Cmd)
+
+	go func() {
+		defer close(ch)
+		for _, cmd := range []parser.Cmd{
+			parser.R{},
+			parser.C{Name: "a"},
+			parser.Up{},
+			parser.P{Type: "string", Name: "b"},
+			parser.Up{},
+			parser.Up{},
+		} {
+			ch <- cmd
+		}
+	}()
+
+	var w strings.Builder
+
+	if err := SerializeDocView(ch, BuiltinNamespaces, &w); err == nil {
+		t.Fatalf("expected error\n")
+	}
+
+	for range ch {
+	}
+}
+
+func TestSerializeDocViewUndeclaredPrefix(t *testing.T) {
+	ch := make(chan parser.Cmd)
+
+	go func() {
+		defer close(ch)
+		for _, cmd := range []parser.Cmd{
+			parser.R{},
+			parser.C{Name: "sling:a"},
+			parser.Up{},
+			parser.Up{},
+		} {
+			ch <- cmd
+		}
+	}()
+
+	var w strings.Builder
+
+	if err := SerializeDocView(ch, BuiltinNamespaces, &w); err == nil {
+		t.Fatalf("expected error\n")
+	}
+
+	for range ch {
+	}
+}
+
+func TestScanPrefixes(t *testing.T) {
+	commands := parser.Parse(strings.NewReader(`
+		r
+		p name jcr:primaryType
+		v rep:root
+		^
+		c sling:a
+		p path b
+		v /c:d/e[2]/f:g
+		^
+		p string xml:lang
+		v h:i
+		^
+		c j
+		^
+		^
+		^
+	`))
+
+	prefixes, err := ScanPrefixes(commands)
+	if err != nil {
+		t.Fatalf("scan: %v\n", err)
+	}
+
+	if expected := []string{"c", "f", "jcr", "rep", "sling"}; !reflect.DeepEqual(prefixes, expected) {
+		t.Fatalf("prefixes: expected %v, got %v\n", expected, prefixes)
+	}
+}
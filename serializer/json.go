@@ -0,0 +1,159 @@
+package serializer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/francescomari/nu/parser"
+)
+
+const (
+	jsonNodeEmpty = iota
+	jsonNodeNamed
+	jsonNodeProperties
+	jsonNodeNodes
+)
+
+// SerializeJSON serializes a stream of commands as a JSON document into a
+// io.Writer. Every node is serialized as an object with an optional `name`, a
+// `properties` array, and a `nodes` array. Every property is serialized as an
+// object with a `name`, a `type`, and a `values` array. Values expressed by a V
+// command are serialized as strings, while values expressed by a X command are
+// serialized as objects whose `binary` field contains the Base64-encoded
+// payload. The document is written while the commands are read, so every
+// property of a node must precede its children. If an error command is
+// returned from the stream, or if an unexpected command is met, SerializeJSON
+// returns with a non-nil error.
+func SerializeJSON(commands <-chan parser.Cmd, w io.Writer) error {
+	s := jsonSerializer{w: bufio.NewWriter(w)}
+
+	for command := range commands {
+		if err := s.serialize(command); err != nil {
+			return err
+		}
+	}
+
+	return s.w.Flush()
+}
+
+type jsonSerializer struct {
+	w          *bufio.Writer
+	nodes      []int
+	inProperty bool
+	firstValue bool
+}
+
+func (s *jsonSerializer) serialize(command parser.Cmd) error {
+	switch cmd := command.(type) {
+	case parser.R:
+		if len(s.nodes) > 0 {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		s.w.WriteString("{")
+		s.nodes = append(s.nodes, jsonNodeEmpty)
+	case parser.C:
+		if len(s.nodes) == 0 || s.inProperty {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		switch s.top() {
+		case jsonNodeEmpty:
+			s.w.WriteString(`"nodes":[`)
+		case jsonNodeNamed:
+			s.w.WriteString(`,"nodes":[`)
+		case jsonNodeProperties:
+			s.w.WriteString(`],"nodes":[`)
+		case jsonNodeNodes:
+			s.w.WriteString(",")
+		}
+		s.setTop(jsonNodeNodes)
+		s.w.WriteString(`{"name":`)
+		s.writeString(cmd.Name)
+		s.nodes = append(s.nodes, jsonNodeNamed)
+	case parser.P:
+		if len(s.nodes) == 0 || s.inProperty {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		switch s.top() {
+		case jsonNodeEmpty:
+			s.w.WriteString(`"properties":[`)
+		case jsonNodeNamed:
+			s.w.WriteString(`,"properties":[`)
+		case jsonNodeProperties:
+			s.w.WriteString(",")
+		case jsonNodeNodes:
+			return fmt.Errorf("property %v follows a child node", cmd.Name)
+		}
+		s.setTop(jsonNodeProperties)
+		s.w.WriteString(`{"name":`)
+		s.writeString(cmd.Name)
+		s.w.WriteString(`,"type":`)
+		s.writeString(cmd.Type)
+		s.w.WriteString(`,"values":[`)
+		s.inProperty = true
+		s.firstValue = true
+	case parser.V:
+		if !s.inProperty {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		s.writeValueSeparator()
+		s.writeString(cmd.Data)
+	case parser.X:
+		if !s.inProperty {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		value, err := binaryToBase64(cmd.Data)
+		if err != nil {
+			return err
+		}
+		s.writeValueSeparator()
+		s.w.WriteString(`{"binary":`)
+		s.writeString(value)
+		s.w.WriteString("}")
+	case parser.Up:
+		if s.inProperty {
+			s.w.WriteString("]}")
+			s.inProperty = false
+			return nil
+		}
+		if len(s.nodes) == 0 {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		switch s.top() {
+		case jsonNodeProperties, jsonNodeNodes:
+			s.w.WriteString("]")
+		}
+		s.w.WriteString("}")
+		s.nodes = s.nodes[:len(s.nodes)-1]
+		if len(s.nodes) == 0 {
+			s.w.WriteString("\n")
+		}
+	case parser.Err:
+		return fmt.Errorf("error at line %v: %v", cmd.Line, cmd.Err)
+	default:
+		return fmt.Errorf("unrecognized command: %#v", cmd)
+	}
+	return nil
+}
+
+func (s *jsonSerializer) top() int {
+	return s.nodes[len(s.nodes)-1]
+}
+
+func (s *jsonSerializer) setTop(state int) {
+	s.nodes[len(s.nodes)-1] = state
+}
+
+func (s *jsonSerializer) writeValueSeparator() {
+	if s.firstValue {
+		s.firstValue = false
+	} else {
+		s.w.WriteString(",")
+	}
+}
+
+func (s *jsonSerializer) writeString(v string) {
+	data, _ := json.Marshal(v)
+	s.w.Write(data)
+}
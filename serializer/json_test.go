@@ -0,0 +1,87 @@
+package serializer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+func TestSerializeJSON(t *testing.T) {
+	ch := make(chan parser.Cmd)
+
+	go func() {
+		defer close(ch)
+		for _, cmd := range []parser.Cmd{
+			parser.R{},
+			parser.P{Type: "string", Name: "a"},
+			parser.V{Data: "b"},
+			parser.V{Data: "c\n\"d\""},
+			parser.Up{},
+			parser.C{Name: "e"},
+			parser.P{Type: "binary", Name: "f"},
+			parser.X{Data: "68656c6c6f"},
+			parser.Up{},
+			parser.P{Type: "long", Name: "g"},
+			parser.Up{},
+			parser.C{Name: "h"},
+			parser.Up{},
+			parser.Up{},
+			parser.C{Name: "i"},
+			parser.Up{},
+			parser.Up{},
+		} {
+			ch <- cmd
+		}
+	}()
+
+	var w strings.Builder
+
+	if err := SerializeJSON(ch, &w); err != nil {
+		t.Fatalf("serialize: %v\n", err)
+	}
+
+	var e string
+
+	e += `{`
+	e += `"properties":[{"name":"a","type":"string","values":["b","c\n\"d\""]}],`
+	e += `"nodes":[`
+	e += `{"name":"e",`
+	e += `"properties":[`
+	e += `{"name":"f","type":"binary","values":[{"binary":"aGVsbG8="}]},`
+	e += `{"name":"g","type":"long","values":[]}],`
+	e += `"nodes":[{"name":"h"}]},`
+	e += `{"name":"i"}]`
+	e += "}\n"
+
+	if e != w.String() {
+		t.Fatalf("unexpected output:\n%v", w.String())
+	}
+}
+
+func TestSerializeJSONPropertyAfterNode(t *testing.T) {
+	ch := make(chan parser.Cmd)
+
+	go func() {
+		defer close(ch)
+		for _, cmd := range []parser.Cmd{
+			parser.R{},
+			parser.C{Name: "a"},
+			parser.Up{},
+			parser.P{Type: "string", Name: "b"},
+			parser.Up{},
+			parser.Up{},
+		} {
+			ch <- cmd
+		}
+	}()
+
+	var w strings.Builder
+
+	if err := SerializeJSON(ch, &w); err == nil {
+		t.Fatalf("expected error\n")
+	}
+
+	for range ch {
+	}
+}
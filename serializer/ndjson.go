@@ -0,0 +1,87 @@
+package serializer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/transform"
+)
+
+type ndjsonNode struct {
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+	Name string `json:"name"`
+}
+
+type ndjsonProperty struct {
+	Kind   string        `json:"kind"`
+	Path   string        `json:"path"`
+	Name   string        `json:"name"`
+	Type   string        `json:"type"`
+	Values []interface{} `json:"values"`
+}
+
+type ndjsonBinary struct {
+	Binary string `json:"binary"`
+}
+
+// SerializeNDJSON serializes a stream of commands as newline-delimited JSON
+// into a io.Writer. Every node and every property is serialized as a JSON
+// object on its own line. Objects describing nodes have a `kind` of `node`, and
+// contain the `path` and the `name` of the node. Objects describing properties
+// have a `kind` of `property`, and contain the `path` of the node the property
+// is attached to, and the `name`, `type`, and `values` of the property. Values
+// are serialized like in SerializeJSON. If an error command is returned from
+// the stream, SerializeNDJSON returns with a non-nil error.
+func SerializeNDJSON(commands <-chan parser.Cmd, w io.Writer) error {
+	var (
+		buffered = bufio.NewWriter(w)
+		encoder  = json.NewEncoder(buffered)
+	)
+
+	for record := range transform.Records(commands) {
+		if record.Err != nil {
+			return fmt.Errorf("error at line %v: %v", record.Line, record.Err)
+		}
+
+		var object interface{}
+
+		switch record.Kind {
+		case transform.NodeRecord:
+			object = ndjsonNode{
+				Kind: "node",
+				Path: record.Path,
+				Name: record.Name,
+			}
+		case transform.PropertyRecord:
+			values := make([]interface{}, 0, len(record.Values))
+			for _, value := range record.Values {
+				if !value.Binary {
+					values = append(values, value.Data)
+					continue
+				}
+				data, err := binaryToBase64(value.Data)
+				if err != nil {
+					return err
+				}
+				values = append(values, ndjsonBinary{data})
+			}
+			object = ndjsonProperty{
+				Kind:   "property",
+				Path:   record.Path,
+				Name:   record.Name,
+				Type:   record.Type,
+				Values: values,
+			}
+		}
+
+		if err := encoder.Encode(object); err != nil {
+			return err
+		}
+	}
+
+	return buffered.Flush()
+}
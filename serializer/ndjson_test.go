@@ -0,0 +1,51 @@
+package serializer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+func TestSerializeNDJSON(t *testing.T) {
+	ch := make(chan parser.Cmd)
+
+	go func() {
+		defer close(ch)
+		for _, cmd := range []parser.Cmd{
+			parser.R{},
+			parser.P{Type: "string", Name: "a"},
+			parser.V{Data: "b"},
+			parser.V{Data: "c"},
+			parser.Up{},
+			parser.C{Name: "d"},
+			parser.P{Type: "binary", Name: "e"},
+			parser.X{Data: "68656c6c6f"},
+			parser.Up{},
+			parser.P{Type: "long", Name: "f"},
+			parser.Up{},
+			parser.Up{},
+			parser.Up{},
+		} {
+			ch <- cmd
+		}
+	}()
+
+	var w strings.Builder
+
+	if err := SerializeNDJSON(ch, &w); err != nil {
+		t.Fatalf("serialize: %v\n", err)
+	}
+
+	var e string
+
+	e += `{"kind":"node","path":"/","name":""}` + "\n"
+	e += `{"kind":"property","path":"/","name":"a","type":"string","values":["b","c"]}` + "\n"
+	e += `{"kind":"node","path":"/d","name":"d"}` + "\n"
+	e += `{"kind":"property","path":"/d","name":"e","type":"binary","values":[{"binary":"aGVsbG8="}]}` + "\n"
+	e += `{"kind":"property","path":"/d","name":"f","type":"long","values":[]}` + "\n"
+
+	if e != w.String() {
+		t.Fatalf("unexpected output:\n%v", w.String())
+	}
+}
@@ -0,0 +1,114 @@
+package serializer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/francescomari/nu/parser"
+)
+
+// SerializeSysView serializes a stream of commands as a JCR System View XML
+// document into a io.Writer. The name of the root node is always `jcr:root`.
+// Property types are converted with parser.JCRType, and the payload of binary
+// values is Base64-encoded. Properties with a number of values other than one
+// are marked as multi-valued. If an error command is returned from the stream,
+// or if an unexpected command is met, SerializeSysView returns with a non-nil
+// error.
+func SerializeSysView(commands <-chan parser.Cmd, w io.Writer) error {
+	s := sysViewSerializer{w: bufio.NewWriter(w)}
+
+	for command := range commands {
+		if err := s.serialize(command); err != nil {
+			return err
+		}
+	}
+
+	return s.w.Flush()
+}
+
+type sysViewSerializer struct {
+	w        *bufio.Writer
+	depth    int
+	property *parser.P
+	values   []string
+}
+
+func (s *sysViewSerializer) serialize(command parser.Cmd) error {
+	switch cmd := command.(type) {
+	case parser.R:
+		if s.depth > 0 {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		s.w.WriteString(xmlHeader)
+		s.w.WriteString(`<sv:node xmlns:sv="` + parser.SysViewNamespace + `" sv:name="` + xmlRootName + `">` + "\n")
+		s.depth++
+	case parser.C:
+		if s.depth == 0 || s.property != nil {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		writeXMLIndent(s.w, s.depth)
+		s.w.WriteString(`<sv:node sv:name="`)
+		writeXMLEscaped(s.w, cmd.Name)
+		s.w.WriteString(`">` + "\n")
+		s.depth++
+	case parser.P:
+		if s.depth == 0 || s.property != nil {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		s.property = &cmd
+		s.values = nil
+	case parser.V:
+		if s.property == nil {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		s.values = append(s.values, cmd.Data)
+	case parser.X:
+		if s.property == nil {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		value, err := binaryToBase64(cmd.Data)
+		if err != nil {
+			return err
+		}
+		s.values = append(s.values, value)
+	case parser.Up:
+		if s.property != nil {
+			s.writeProperty()
+			s.property = nil
+			return nil
+		}
+		if s.depth == 0 {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		s.depth--
+		writeXMLIndent(s.w, s.depth)
+		s.w.WriteString("</sv:node>\n")
+	case parser.Err:
+		return fmt.Errorf("error at line %v: %v", cmd.Line, cmd.Err)
+	default:
+		return fmt.Errorf("unrecognized command: %#v", cmd)
+	}
+	return nil
+}
+
+func (s *sysViewSerializer) writeProperty() {
+	writeXMLIndent(s.w, s.depth)
+	s.w.WriteString(`<sv:property sv:name="`)
+	writeXMLEscaped(s.w, s.property.Name)
+	s.w.WriteString(`" sv:type="`)
+	writeXMLEscaped(s.w, parser.JCRType(s.property.Type))
+	s.w.WriteString(`"`)
+	if len(s.values) != 1 {
+		s.w.WriteString(` sv:multiple="true"`)
+	}
+	s.w.WriteString(">\n")
+	for _, value := range s.values {
+		writeXMLIndent(s.w, s.depth+1)
+		s.w.WriteString("<sv:value>")
+		writeXMLEscaped(s.w, value)
+		s.w.WriteString("</sv:value>\n")
+	}
+	writeXMLIndent(s.w, s.depth)
+	s.w.WriteString("</sv:property>\n")
+}
@@ -0,0 +1,62 @@
+package serializer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+func TestSerializeSysView(t *testing.T) {
+	ch := make(chan parser.Cmd)
+
+	go func() {
+		defer close(ch)
+		for _, cmd := range []parser.Cmd{
+			parser.R{},
+			parser.P{Type: "name", Name: "jcr:primaryType"},
+			parser.V{Data: "rep:root"},
+			parser.Up{},
+			parser.C{Name: "a&b"},
+			parser.P{Type: "binary", Name: "c"},
+			parser.X{Data: "68656c6c6f"},
+			parser.Up{},
+			parser.P{Type: "string", Name: "d"},
+			parser.V{Data: "<e>"},
+			parser.V{Data: "f"},
+			parser.Up{},
+			parser.Up{},
+			parser.Up{},
+		} {
+			ch <- cmd
+		}
+	}()
+
+	var w strings.Builder
+
+	if err := SerializeSysView(ch, &w); err != nil {
+		t.Fatalf("serialize: %v\n", err)
+	}
+
+	var e string
+
+	e += `<?xml version="1.0" encoding="UTF-8"?>` + "\n"
+	e += `<sv:node xmlns:sv="http://www.jcp.org/jcr/sv/1.0" sv:name="jcr:root">` + "\n"
+	e += `  <sv:property sv:name="jcr:primaryType" sv:type="Name">` + "\n"
+	e += `    <sv:value>rep:root</sv:value>` + "\n"
+	e += `  </sv:property>` + "\n"
+	e += `  <sv:node sv:name="a&amp;b">` + "\n"
+	e += `    <sv:property sv:name="c" sv:type="Binary">` + "\n"
+	e += `      <sv:value>aGVsbG8=</sv:value>` + "\n"
+	e += `    </sv:property>` + "\n"
+	e += `    <sv:property sv:name="d" sv:type="String" sv:multiple="true">` + "\n"
+	e += `      <sv:value>&lt;e&gt;</sv:value>` + "\n"
+	e += `      <sv:value>f</sv:value>` + "\n"
+	e += `    </sv:property>` + "\n"
+	e += `  </sv:node>` + "\n"
+	e += `</sv:node>` + "\n"
+
+	if e != w.String() {
+		t.Fatalf("unexpected output:\n%v", w.String())
+	}
+}
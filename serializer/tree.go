@@ -0,0 +1,307 @@
+package serializer
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/francescomari/nu/parser"
+)
+
+const (
+	treeBranch     = "├── "
+	treeLastBranch = "└── "
+	treeIndent     = "│   "
+	treeLastIndent = "    "
+	// treeMaxValueLength is the maximum length, in runes, of the values of a
+	// property printed by SerializeTree.
+	treeMaxValueLength = 40
+)
+
+// TreeOptions controls the output of SerializeTree.
+type TreeOptions struct {
+	// Depth is the maximum depth of the printed nodes. A depth of zero means
+	// that every node is printed.
+	Depth int
+	// Properties controls whether properties are printed.
+	Properties bool
+	// Counts controls whether the number of children of every node is
+	// printed.
+	Counts bool
+}
+
+// TreeShape describes the shape of the tree printed by SerializeTree. For every
+// printed node and property, in order, it records whether the entry is the last
+// one of its node and, for nodes, the number of their children. Since the
+// shape of the lines depends on what follows them, it can't be known while the
+// export is printed, and is computed by ScanTree with a first pass over the
+// export instead.
+type TreeShape struct {
+	entries []treeEntry
+}
+
+type treeEntry struct {
+	last     bool
+	children int
+}
+
+type treeScanNode struct {
+	depth int
+	// entry is the index of the entry of the node, or -1 if the node is not
+	// printed.
+	entry int
+	// lastEntry is the index of the last printed entry of the node, or -1 if
+	// none of its entries is printed.
+	lastEntry int
+}
+
+// ScanTree reads a stream of commands and returns the shape of the tree that
+// SerializeTree prints for the same stream and options. Only a few bytes are
+// retained for every printed node and property. If an error command is
+// returned from the stream, or if an unexpected command is met, ScanTree
+// returns with a non-nil error.
+func ScanTree(commands <-chan parser.Cmd, options TreeOptions) (*TreeShape, error) {
+	var (
+		shape      TreeShape
+		nodes      []treeScanNode
+		inProperty bool
+	)
+
+	isVisible := func(depth int) bool {
+		return options.Depth <= 0 || depth <= options.Depth
+	}
+
+	addEntry := func() int {
+		shape.entries = append(shape.entries, treeEntry{})
+		return len(shape.entries) - 1
+	}
+
+	for command := range commands {
+		switch cmd := command.(type) {
+		case parser.R:
+			if len(nodes) > 0 {
+				return nil, fmt.Errorf("unexpected command: %#v", cmd)
+			}
+			nodes = append(nodes, treeScanNode{entry: -1, lastEntry: -1})
+		case parser.C:
+			if len(nodes) == 0 || inProperty {
+				return nil, fmt.Errorf("unexpected command: %#v", cmd)
+			}
+			parent := &nodes[len(nodes)-1]
+			if parent.entry >= 0 {
+				shape.entries[parent.entry].children++
+			}
+			node := treeScanNode{depth: parent.depth + 1, entry: -1, lastEntry: -1}
+			if isVisible(node.depth) {
+				node.entry = addEntry()
+				parent.lastEntry = node.entry
+			}
+			nodes = append(nodes, node)
+		case parser.P:
+			if len(nodes) == 0 || inProperty {
+				return nil, fmt.Errorf("unexpected command: %#v", cmd)
+			}
+			inProperty = true
+			if node := &nodes[len(nodes)-1]; options.Properties && isVisible(node.depth+1) {
+				node.lastEntry = addEntry()
+			}
+		case parser.V, parser.X:
+			if !inProperty {
+				return nil, fmt.Errorf("unexpected command: %#v", cmd)
+			}
+		case parser.Up:
+			if inProperty {
+				inProperty = false
+				continue
+			}
+			if len(nodes) == 0 {
+				return nil, fmt.Errorf("unexpected command: %#v", cmd)
+			}
+			if node := nodes[len(nodes)-1]; node.lastEntry >= 0 {
+				shape.entries[node.lastEntry].last = true
+			}
+			nodes = nodes[:len(nodes)-1]
+		case parser.Err:
+			return nil, fmt.Errorf("error at line %v: %v", cmd.Line, cmd.Err)
+		default:
+			return nil, fmt.Errorf("unrecognized command: %#v", cmd)
+		}
+	}
+
+	return &shape, nil
+}
+
+// SerializeTree prints a stream of commands as a tree into a io.Writer, like the
+// Unix `tree` utility. Properties, if printed, precede the children of their
+// node and are prefixed with `@`. The output is followed by a summary with the
+// total number of nodes and properties. The shape of the tree must be computed
+// by ScanTree over the same stream of commands and with the same options, so
+// that every line is written as soon as it is read. If an error command is
+// returned from the stream, or if an unexpected command is met, SerializeTree
+// returns with a non-nil error.
+func SerializeTree(commands <-chan parser.Cmd, shape *TreeShape, w io.Writer, options TreeOptions) error {
+	s := treeSerializer{
+		w:       bufio.NewWriter(w),
+		options: options,
+		shape:   shape,
+	}
+
+	for command := range commands {
+		if err := s.serialize(command); err != nil {
+			return err
+		}
+	}
+
+	return s.w.Flush()
+}
+
+type treeNode struct {
+	depth int
+	// prefix is the prefix of the lines of the entries of this node.
+	prefix string
+}
+
+type treeSerializer struct {
+	w       *bufio.Writer
+	options TreeOptions
+	shape   *TreeShape
+	// entry is the index in the shape of the next printed entry.
+	entry    int
+	nodes    []treeNode
+	property *parser.P
+	// propertyEntry is the entry of the current property, if printed.
+	propertyEntry *treeEntry
+	values        []string
+	properties    int
+	total         int
+}
+
+func (s *treeSerializer) serialize(command parser.Cmd) error {
+	switch cmd := command.(type) {
+	case parser.R:
+		if len(s.nodes) > 0 {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		s.w.WriteString("/\n")
+		s.nodes = append(s.nodes, treeNode{})
+		s.total++
+	case parser.C:
+		if len(s.nodes) == 0 || s.property != nil {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		parent := s.top()
+		node := treeNode{depth: parent.depth + 1}
+		if s.isVisible(node.depth) {
+			entry, err := s.nextEntry()
+			if err != nil {
+				return err
+			}
+			s.writeEntry(parent, entry, s.nodeLine(cmd.Name, entry.children))
+			node.prefix = parent.prefix + treeIndent
+			if entry.last {
+				node.prefix = parent.prefix + treeLastIndent
+			}
+		}
+		s.nodes = append(s.nodes, node)
+		s.total++
+	case parser.P:
+		if len(s.nodes) == 0 || s.property != nil {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		s.property = &cmd
+		s.propertyEntry = nil
+		s.values = nil
+		s.properties++
+		if s.options.Properties && s.isVisible(s.top().depth+1) {
+			entry, err := s.nextEntry()
+			if err != nil {
+				return err
+			}
+			s.propertyEntry = &entry
+		}
+	case parser.V:
+		if s.property == nil {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		s.values = append(s.values, strconv.Quote(cmd.Data))
+	case parser.X:
+		if s.property == nil {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		s.values = append(s.values, fmt.Sprintf("<binary, %v bytes>", hex.DecodedLen(len(cmd.Data))))
+	case parser.Up:
+		if s.property != nil {
+			if s.propertyEntry != nil {
+				s.writeEntry(s.top(), *s.propertyEntry, s.propertyLine())
+			}
+			s.property = nil
+			return nil
+		}
+		if len(s.nodes) == 0 {
+			return fmt.Errorf("unexpected command: %#v", cmd)
+		}
+		s.nodes = s.nodes[:len(s.nodes)-1]
+		if len(s.nodes) == 0 {
+			fmt.Fprintf(s.w, "\n%v nodes, %v properties\n", s.total, s.properties)
+		}
+	case parser.Err:
+		return fmt.Errorf("error at line %v: %v", cmd.Line, cmd.Err)
+	default:
+		return fmt.Errorf("unrecognized command: %#v", cmd)
+	}
+	return nil
+}
+
+func (s *treeSerializer) top() treeNode {
+	return s.nodes[len(s.nodes)-1]
+}
+
+func (s *treeSerializer) isVisible(depth int) bool {
+	return s.options.Depth <= 0 || depth <= s.options.Depth
+}
+
+// nextEntry returns the shape of the next printed entry.
+func (s *treeSerializer) nextEntry() (treeEntry, error) {
+	if s.shape == nil || s.entry >= len(s.shape.entries) {
+		return treeEntry{}, errors.New("the shape of the tree doesn't match the export")
+	}
+	entry := s.shape.entries[s.entry]
+	s.entry++
+	return entry, nil
+}
+
+// writeEntry writes the line of an entry of a node.
+func (s *treeSerializer) writeEntry(node treeNode, entry treeEntry, line string) {
+	branch := treeBranch
+	if entry.last {
+		branch = treeLastBranch
+	}
+	s.w.WriteString(node.prefix + branch + line + "\n")
+}
+
+func (s *treeSerializer) nodeLine(name string, children int) string {
+	if !s.options.Counts {
+		return name
+	}
+	if children == 1 {
+		return fmt.Sprintf("%v (1 child)", name)
+	}
+	return fmt.Sprintf("%v (%v children)", name, children)
+}
+
+func (s *treeSerializer) propertyLine() string {
+	var value string
+	if len(s.values) == 1 {
+		value = s.values[0]
+	} else {
+		value = "[" + strings.Join(s.values, ", ") + "]"
+	}
+	if runes := []rune(value); len(runes) > treeMaxValueLength {
+		value = string(runes[:treeMaxValueLength-3]) + "..."
+	}
+	return fmt.Sprintf("@%v (%v) = %v", s.property.Name, s.property.Type, value)
+}
@@ -0,0 +1,110 @@
+package serializer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+func treeCommands() <-chan parser.Cmd {
+	return parser.Parse(strings.NewReader(`
+		r
+		p name jcr:primaryType
+		v rep:root
+		^
+		c a
+		p string t
+		v a very long value that is going to be truncated
+		^
+		c a1
+		c a11
+		^
+		^
+		c a2
+		p binary b
+		x cafe
+		^
+		p long c
+		v 1
+		v 2
+		^
+		^
+		^
+		c b
+		c b1
+		^
+		^
+		^
+	`))
+}
+
+func TestSerializeTree(t *testing.T) {
+	tests := []struct {
+		options  TreeOptions
+		expected string
+	}{
+		{
+			TreeOptions{},
+			"/\n" +
+				"├── a\n" +
+				"│   ├── a1\n" +
+				"│   │   └── a11\n" +
+				"│   └── a2\n" +
+				"└── b\n" +
+				"    └── b1\n" +
+				"\n7 nodes, 4 properties\n",
+		},
+		{
+			TreeOptions{Depth: 1, Counts: true},
+			"/\n" +
+				"├── a (2 children)\n" +
+				"└── b (1 child)\n" +
+				"\n7 nodes, 4 properties\n",
+		},
+		{
+			TreeOptions{Depth: 2, Properties: true},
+			"/\n" +
+				"├── @jcr:primaryType (name) = \"rep:root\"\n" +
+				"├── a\n" +
+				"│   ├── @t (string) = \"a very long value that is going to b...\n" +
+				"│   ├── a1\n" +
+				"│   └── a2\n" +
+				"└── b\n" +
+				"    └── b1\n" +
+				"\n7 nodes, 4 properties\n",
+		},
+		{
+			TreeOptions{Properties: true},
+			"/\n" +
+				"├── @jcr:primaryType (name) = \"rep:root\"\n" +
+				"├── a\n" +
+				"│   ├── @t (string) = \"a very long value that is going to b...\n" +
+				"│   ├── a1\n" +
+				"│   │   └── a11\n" +
+				"│   └── a2\n" +
+				"│       ├── @b (binary) = <binary, 2 bytes>\n" +
+				"│       └── @c (long) = [\"1\", \"2\"]\n" +
+				"└── b\n" +
+				"    └── b1\n" +
+				"\n7 nodes, 4 properties\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var w strings.Builder
+
+		shape, err := ScanTree(treeCommands(), tt.options)
+		if err != nil {
+			t.Fatalf("scan: %v\n", err)
+		}
+
+		if err := SerializeTree(treeCommands(), shape, &w, tt.options); err != nil {
+			t.Fatalf("serialize: %v\n", err)
+		}
+
+		if tt.expected != w.String() {
+			t.Errorf("options %+v: unexpected output:\n%v", tt.options, w.String())
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package serializer
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+const (
+	xmlHeader = `<?xml version="1.0" encoding="UTF-8"?>` + "\n"
+	// xmlRootName is the name of the root node in JCR XML.
+	xmlRootName = "jcr:root"
+)
+
+// BuiltinNamespaces maps the prefixes of the namespaces built into a JCR
+// repository to their URIs.
+var BuiltinNamespaces = map[string]string{
+	"jcr": "http://www.jcp.org/jcr/1.0",
+	"nt":  "http://www.jcp.org/jcr/nt/1.0",
+	"mix": "http://www.jcp.org/jcr/mix/1.0",
+	"rep": "internal",
+}
+
+// binaryToBase64 converts the payload of a X command to its Base64 encoding.
+func binaryToBase64(data string) (string, error) {
+	decoded, err := hex.DecodeString(data)
+	if err != nil {
+		return "", fmt.Errorf("decoding binary value: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(decoded), nil
+}
+
+// writeXMLEscaped writes a string escaped to be used as XML character data or
+// as the value of an attribute.
+func writeXMLEscaped(w io.Writer, s string) {
+	xml.EscapeText(w, []byte(s))
+}
+
+func writeXMLIndent(w io.Writer, depth int) {
+	io.WriteString(w, strings.Repeat("  ", depth))
+}
+
+// escapeXMLName escapes a JCR name so that it can be used as the name of an
+// XML element or attribute. A prefix, if present, is preserved. Characters that
+// are not allowed in an XML name are escaped as `_xHHHH_`, where `HHHH` is the
+// hexadecimal code of the character, as described by ISO 9075. Characters
+// outside the Basic Multilingual Plane are escaped as a UTF-16 surrogate pair.
+func escapeXMLName(name string) string {
+	if i := strings.Index(name, ":"); i > 0 && i < len(name)-1 {
+		return escapeXMLNCName(name[:i]) + ":" + escapeXMLNCName(name[i+1:])
+	}
+	return escapeXMLNCName(name)
+}
+
+// xmlPrefix returns the escaped prefix of a JCR name, as used by escapeXMLName,
+// or an empty string if the name has no prefix.
+func xmlPrefix(name string) string {
+	if i := strings.Index(name, ":"); i > 0 && i < len(name)-1 {
+		return escapeXMLNCName(name[:i])
+	}
+	return ""
+}
+
+func escapeXMLNCName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' && isXMLEscapeSequence(name[i:]):
+			b.WriteString("_x005F_")
+		case i == 0 && isXMLNameStart(r):
+			b.WriteRune(r)
+		case i > 0 && isXMLNameChar(r):
+			b.WriteRune(r)
+		case r > 0xFFFF:
+			r1, r2 := utf16.EncodeRune(r)
+			fmt.Fprintf(&b, "_x%04X__x%04X_", r1, r2)
+		default:
+			fmt.Fprintf(&b, "_x%04X_", r)
+		}
+	}
+	return b.String()
+}
+
+// escapeXMLSpaces escapes the spaces in a value of a multi-valued property
+// serialized in Document View XML, where values are separated by spaces.
+func escapeXMLSpaces(value string) string {
+	var b strings.Builder
+	for i, r := range value {
+		switch {
+		case r == '_' && isXMLEscapeSequence(value[i:]):
+			b.WriteString("_x005F_")
+		case r == ' ':
+			b.WriteString("_x0020_")
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func isXMLEscapeSequence(s string) bool {
+	if len(s) < 7 || s[0] != '_' || s[1] != 'x' || s[6] != '_' {
+		return false
+	}
+	for _, c := range s[2:6] {
+		if !unicode.Is(unicode.ASCII_Hex_Digit, c) {
+			return false
+		}
+	}
+	return true
+}
+
+func isXMLNameStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isXMLNameChar(r rune) bool {
+	return isXMLNameStart(r) || r == '-' || r == '.' || r == 0xB7 || unicode.IsDigit(r) || unicode.IsMark(r)
+}
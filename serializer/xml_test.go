@@ -0,0 +1,46 @@
+package serializer
+
+import "testing"
+
+func TestEscapeXMLName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"name", "name"},
+		{"jcr:content", "jcr:content"},
+		{"my name", "my_x0020_name"},
+		{"1st", "_x0031_st"},
+		{"a_x0020_b", "a_x005F_x0020_b"},
+		{"a_b", "a_b"},
+		{":a", "_x003A_a"},
+		{"a:", "a_x003A_"},
+		{"a:b:c", "a:b_x003A_c"},
+		{"-a.b", "_x002D_a.b"},
+		{"a\U0001F600b", "a_xD83D__xDE00_b"},
+		{"\U0001D518", "\U0001D518"},
+	}
+
+	for _, tt := range tests {
+		if escaped := escapeXMLName(tt.name); escaped != tt.expected {
+			t.Errorf("name %v: expected %v, got %v\n", tt.name, tt.expected, escaped)
+		}
+	}
+}
+
+func TestEscapeXMLSpaces(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"value", "value"},
+		{"a b", "a_x0020_b"},
+		{"a_x0020_b", "a_x005F_x0020_b"},
+	}
+
+	for _, tt := range tests {
+		if escaped := escapeXMLSpaces(tt.value); escaped != tt.expected {
+			t.Errorf("value %v: expected %v, got %v\n", tt.value, tt.expected, escaped)
+		}
+	}
+}
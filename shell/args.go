@@ -0,0 +1,97 @@
+package shell
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// splitArgs splits a command line in words. Words are separated by whitespace.
+// Whitespace can be included in a word by escaping it with a backslash, or by
+// enclosing it in single or double quotes.
+func splitArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if escaped || quote != 0 {
+		return nil, errors.New("unterminated escape or quote")
+	}
+
+	if inWord {
+		args = append(args, word.String())
+	}
+
+	return args, nil
+}
+
+// lastWordStart returns the offset in bytes of the beginning of the last word
+// in a command line.
+func lastWordStart(line string) int {
+	var (
+		start   int
+		quote   rune
+		escaped bool
+	)
+
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+		case r == '"' || r == '\'':
+			quote = r
+		case unicode.IsSpace(r):
+			start = i + 1
+		}
+	}
+
+	return start
+}
+
+// escapeArg escapes a word so that splitArgs reads it back unchanged.
+func escapeArg(arg string) string {
+	var b strings.Builder
+	for _, r := range arg {
+		if unicode.IsSpace(r) || r == '\\' || r == '"' || r == '\'' {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
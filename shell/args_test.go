@@ -0,0 +1,55 @@
+package shell
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{"", nil},
+		{"  ", nil},
+		{"a b", []string{"a", "b"}},
+		{" a  b ", []string{"a", "b"}},
+		{`a\ b`, []string{"a b"}},
+		{`"a b" 'c d'`, []string{"a b", "c d"}},
+		{`'a\b'`, []string{`a\b`}},
+		{`"a\"b"`, []string{`a"b`}},
+		{`""`, []string{""}},
+	}
+
+	for _, tt := range tests {
+		args, err := splitArgs(tt.line)
+		if err != nil {
+			t.Errorf("splitting %q: %v\n", tt.line, err)
+			continue
+		}
+		if strings.Join(args, "|") != strings.Join(tt.expected, "|") || len(args) != len(tt.expected) {
+			t.Errorf("splitting %q: expected %q, got %q\n", tt.line, tt.expected, args)
+		}
+	}
+}
+
+func TestSplitArgsInvalid(t *testing.T) {
+	for _, line := range []string{`a\`, `"a`, `'a`} {
+		if _, err := splitArgs(line); err == nil {
+			t.Errorf("splitting %q: expected error\n", line)
+		}
+	}
+}
+
+func TestEscapeArg(t *testing.T) {
+	for _, arg := range []string{"a", "a b", `a\b`, `a"b'c`} {
+		args, err := splitArgs(escapeArg(arg))
+		if err != nil {
+			t.Errorf("escaping %q: %v\n", arg, err)
+			continue
+		}
+		if len(args) != 1 || args[0] != arg {
+			t.Errorf("escaping %q: got %q\n", arg, args)
+		}
+	}
+}
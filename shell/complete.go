@@ -0,0 +1,87 @@
+package shell
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/francescomari/nu/paths"
+)
+
+// Complete returns the completions for a partial command line. Every
+// completion is a full command line. The first word is completed with the
+// names of the commands. Any other word is completed with the names of the
+// children of a node.
+func (s *Shell) Complete(line string) []string {
+	var (
+		start = lastWordStart(line)
+		head  = line[:start]
+		word  string
+	)
+
+	if words, err := splitArgs(line[start:]); err != nil {
+		return nil
+	} else if len(words) == 1 {
+		word = words[0]
+	}
+
+	if strings.TrimSpace(head) == "" {
+		return s.completeCommand(head, word)
+	}
+
+	return s.completePath(head, word)
+}
+
+func (s *Shell) completeCommand(head, word string) []string {
+	var result []string
+	for name := range commands {
+		if strings.HasPrefix(name, word) {
+			result = append(result, head+name+" ")
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (s *Shell) completePath(head, word string) []string {
+	var (
+		slash  = strings.LastIndex(word, "/") + 1
+		dir    = word[:slash]
+		prefix = word[slash:]
+	)
+
+	parent := s.cwd
+	if dir != "" {
+		parent = s.resolve(dir)
+	}
+
+	e, ok := s.index.Lookup(parent)
+	if !ok {
+		return nil
+	}
+
+	var result []string
+	for _, child := range s.index.Children(e) {
+		if n := paths.Name(child.Path); strings.HasPrefix(n, prefix) {
+			result = append(result, head+escapeArg(dir+n)+"/")
+		}
+	}
+	return result
+}
+
+// commonPrefix returns the longest common prefix of a list of strings.
+func commonPrefix(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	prefix := values[0]
+	for _, v := range values[1:] {
+		for !strings.HasPrefix(v, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	for !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return prefix
+}
@@ -0,0 +1,203 @@
+package shell
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	keyCtrlA     = 1
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyBackspace = 8
+	keyTab       = 9
+	keyLineFeed  = 10
+	keyReturn    = 13
+	keyCtrlU     = 21
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// editor reads lines from a terminal in raw mode, and supports cursor
+// movement, history, and completion.
+type editor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	complete func(string) []string
+}
+
+type editorState struct {
+	prompt string
+	buffer []rune
+	cursor int
+	// history contains the previous lines, followed by the line being edited.
+	history []string
+	// position is the index in history of the line being edited.
+	position int
+}
+
+// readLine reads a line. readLine returns io.EOF if the input is terminated,
+// or if the user presses Ctrl-D on an empty line.
+func (e *editor) readLine(prompt string, history []string) (string, error) {
+	s := editorState{
+		prompt:   prompt,
+		history:  append(append([]string(nil), history...), ""),
+		position: len(history),
+	}
+
+	e.redraw(&s)
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case keyReturn, keyLineFeed:
+			io.WriteString(e.out, "\r\n")
+			return string(s.buffer), nil
+		case keyCtrlC:
+			io.WriteString(e.out, "^C\r\n")
+			return "", nil
+		case keyCtrlD:
+			if len(s.buffer) == 0 {
+				io.WriteString(e.out, "\r\n")
+				return "", io.EOF
+			}
+			s.delete()
+		case keyBackspace, keyDelete:
+			if s.cursor > 0 {
+				s.cursor--
+				s.delete()
+			}
+		case keyCtrlA:
+			s.cursor = 0
+		case keyCtrlE:
+			s.cursor = len(s.buffer)
+		case keyCtrlU:
+			s.buffer = s.buffer[s.cursor:]
+			s.cursor = 0
+		case keyTab:
+			e.completeLine(&s)
+		case keyEscape:
+			if err := e.escape(&s); err != nil {
+				return "", err
+			}
+		default:
+			if r >= ' ' {
+				s.insert(r)
+			}
+		}
+
+		e.redraw(&s)
+	}
+}
+
+func (e *editor) escape(s *editorState) error {
+	r, _, err := e.in.ReadRune()
+	if err != nil {
+		return err
+	}
+	if r != '[' && r != 'O' {
+		return nil
+	}
+
+	r, _, err = e.in.ReadRune()
+	if err != nil {
+		return err
+	}
+
+	switch r {
+	case 'A':
+		s.moveHistory(-1)
+	case 'B':
+		s.moveHistory(1)
+	case 'C':
+		if s.cursor < len(s.buffer) {
+			s.cursor++
+		}
+	case 'D':
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case 'H':
+		s.cursor = 0
+	case 'F':
+		s.cursor = len(s.buffer)
+	case '3':
+		if r, _, err = e.in.ReadRune(); err != nil {
+			return err
+		}
+		if r == '~' {
+			s.delete()
+		}
+	}
+
+	return nil
+}
+
+func (e *editor) completeLine(s *editorState) {
+	var (
+		head        = string(s.buffer[:s.cursor])
+		tail        = string(s.buffer[s.cursor:])
+		completions = e.complete(head)
+	)
+
+	if len(completions) == 0 {
+		return
+	}
+
+	if prefix := commonPrefix(completions); len(prefix) > len(head) {
+		s.buffer = []rune(prefix + tail)
+		s.cursor = len([]rune(prefix))
+		return
+	}
+
+	if len(completions) == 1 {
+		return
+	}
+
+	var words []string
+	for _, c := range completions {
+		c = strings.TrimRight(c, " ")
+		word := strings.TrimSuffix(c[lastWordStart(c):], "/")
+		words = append(words, word[strings.LastIndex(word, "/")+1:])
+	}
+
+	fmt.Fprintf(e.out, "\r\n%v\r\n", strings.Join(words, "  "))
+}
+
+func (e *editor) redraw(s *editorState) {
+	fmt.Fprintf(e.out, "\r%v%v\x1b[K", s.prompt, string(s.buffer))
+	if n := len(s.buffer) - s.cursor; n > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", n)
+	}
+}
+
+func (s *editorState) insert(r rune) {
+	s.buffer = append(s.buffer, 0)
+	copy(s.buffer[s.cursor+1:], s.buffer[s.cursor:])
+	s.buffer[s.cursor] = r
+	s.cursor++
+}
+
+func (s *editorState) delete() {
+	if s.cursor < len(s.buffer) {
+		s.buffer = append(s.buffer[:s.cursor], s.buffer[s.cursor+1:]...)
+	}
+}
+
+func (s *editorState) moveHistory(delta int) {
+	position := s.position + delta
+	if position < 0 || position >= len(s.history) {
+		return
+	}
+	s.history[s.position] = string(s.buffer)
+	s.position = position
+	s.buffer = []rune(s.history[position])
+	s.cursor = len(s.buffer)
+}
@@ -0,0 +1,65 @@
+package shell
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func newEditor(input string) *editor {
+	return &editor{
+		in:  bufio.NewReader(strings.NewReader(input)),
+		out: ioutil.Discard,
+		complete: func(line string) []string {
+			var result []string
+			for _, c := range []string{"cat ", "cd "} {
+				if strings.HasPrefix(c, line) {
+					result = append(result, c)
+				}
+			}
+			return result
+		},
+	}
+}
+
+func TestEditorReadLine(t *testing.T) {
+	tests := []struct {
+		input    string
+		history  []string
+		expected string
+	}{
+		{"abc\r", nil, "abc"},
+		{"abc\x7fd\r", nil, "abd"},
+		{"ac\x1b[Db\r", nil, "abc"},
+		{"bc\x01a\x05d\r", nil, "abcd"},
+		{"ab\x01\x1b[3~\r", nil, "b"},
+		{"ab\x15c\r", nil, "c"},
+		{"ca\t\r", nil, "cat "},
+		{"c\t\r", nil, "c"},
+		{"\x1b[A\r", []string{"first", "second"}, "second"},
+		{"\x1b[A\x1b[A\r", []string{"first", "second"}, "first"},
+		{"x\x1b[A\x1b[B\r", []string{"first"}, "x"},
+		{"abc\x03", nil, ""},
+	}
+
+	for _, tt := range tests {
+		line, err := newEditor(tt.input).readLine("> ", tt.history)
+		if err != nil {
+			t.Errorf("input %q: %v\n", tt.input, err)
+			continue
+		}
+		if line != tt.expected {
+			t.Errorf("input %q: expected %q, got %q\n", tt.input, tt.expected, line)
+		}
+	}
+}
+
+func TestEditorEOF(t *testing.T) {
+	for _, input := range []string{"\x04", "", "abc"} {
+		if _, err := newEditor(input).readLine("> ", nil); err != io.EOF {
+			t.Errorf("input %q: expected EOF, got %v\n", input, err)
+		}
+	}
+}
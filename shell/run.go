@@ -0,0 +1,51 @@
+package shell
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Run reads commands from in and executes them, until the input is terminated
+// or the exit command is executed. If in is a terminal, Run supports line
+// editing, history, and completion.
+func Run(s *Shell, in *os.File, out io.Writer) error {
+	e := editor{
+		in:       bufio.NewReader(in),
+		out:      out,
+		complete: s.Complete,
+	}
+
+	for {
+		var (
+			line string
+			err  error
+		)
+
+		if restore, rawErr := makeRaw(int(in.Fd())); rawErr == nil {
+			line, err = e.readLine(s.Prompt(), s.History())
+			restore()
+		} else {
+			fmt.Fprint(out, s.Prompt())
+			line, err = e.in.ReadString('\n')
+			if err == io.EOF && line != "" {
+				err = nil
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.Execute(strings.TrimSpace(line)); err == ErrExit {
+			return nil
+		} else if err != nil {
+			fmt.Fprintf(out, "Error: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package shell
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/francescomari/nu/index"
+	"github.com/francescomari/nu/paths"
+	"github.com/francescomari/nu/transform"
+)
+
+var (
+	// ErrExit is returned by Execute when the shell should terminate.
+	ErrExit = errors.New("exit")
+)
+
+// Shell navigates an export through its index. The export is never loaded in
+// memory. Only the parts of the export needed to execute a command are read.
+type Shell struct {
+	index   *index.Index
+	export  io.ReaderAt
+	out     io.Writer
+	cwd     string
+	history []string
+}
+
+type command struct {
+	usage   string
+	help    string
+	execute func(s *Shell, args []string) error
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"cat":     {"cat [path]", "Print the properties of a node, or a single property", (*Shell).cat},
+		"cd":      {"cd [path]", "Change the current node", (*Shell).cd},
+		"du":      {"du [path]", "Print the size of the subtree of every child of a node", (*Shell).du},
+		"exit":    {"exit", "Terminate the shell", (*Shell).exit},
+		"find":    {"find [path] [pattern]", "Print the paths of the descendants whose name matches a pattern", (*Shell).find},
+		"help":    {"help", "Print the list of commands", (*Shell).help},
+		"history": {"history", "Print the commands executed so far", (*Shell).printHistory},
+		"ls":      {"ls [path]", "Print the children and the properties of a node", (*Shell).ls},
+		"pwd":     {"pwd", "Print the path of the current node", (*Shell).pwd},
+		"stat":    {"stat [path]", "Print information about a node", (*Shell).stat},
+	}
+}
+
+// New creates a new Shell for an export and its index. The output of every
+// command is written to out.
+func New(ix *index.Index, export io.ReaderAt, out io.Writer) *Shell {
+	return &Shell{
+		index:  ix,
+		export: export,
+		out:    out,
+		cwd:    "/",
+	}
+}
+
+// Prompt returns the prompt to show before reading a command.
+func (s *Shell) Prompt() string {
+	return fmt.Sprintf("nu:%v> ", s.cwd)
+}
+
+// History returns the commands executed so far.
+func (s *Shell) History() []string {
+	return s.history
+}
+
+// Execute executes a command line. Execute returns ErrExit if the shell should
+// terminate.
+func (s *Shell) Execute(line string) error {
+	fields, err := splitArgs(line)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	s.history = append(s.history, line)
+
+	cmd, ok := commands[fields[0]]
+	if !ok {
+		return fmt.Errorf("unknown command: %v", fields[0])
+	}
+
+	return cmd.execute(s, fields[1:])
+}
+
+// resolve returns the fully qualified path of a path relative to the current
+// node. An empty path resolves to the current node.
+func (s *Shell) resolve(p string) string {
+	if strings.HasPrefix(p, "/") {
+		return paths.Clean(p)
+	}
+	return paths.Join(s.cwd, p)
+}
+
+func (s *Shell) lookup(args []string) (index.Entry, error) {
+	if len(args) > 1 {
+		return index.Entry{}, errors.New("too many arguments")
+	}
+
+	p := s.cwd
+	if len(args) == 1 {
+		p = s.resolve(args[0])
+	}
+
+	e, ok := s.index.Lookup(p)
+	if !ok {
+		return index.Entry{}, fmt.Errorf("%v: no such node", p)
+	}
+
+	return e, nil
+}
+
+func (s *Shell) properties(e index.Entry) ([]transform.Record, error) {
+	var properties []transform.Record
+
+	for r := range transform.Records(s.index.Node(s.export, e)) {
+		if r.Err != nil {
+			return nil, fmt.Errorf("error at line %v: %v", r.Line, r.Err)
+		}
+		if r.Kind == transform.PropertyRecord {
+			properties = append(properties, r)
+		}
+	}
+
+	return properties, nil
+}
+
+func (s *Shell) cat(args []string) error {
+	e, err := s.lookup(args)
+	if err == nil {
+		properties, err := s.properties(e)
+		if err != nil {
+			return err
+		}
+		for _, p := range properties {
+			s.printProperty(p)
+		}
+		return nil
+	}
+
+	if len(args) != 1 {
+		return err
+	}
+
+	// The argument might be the path of a property.
+	p := s.resolve(args[0])
+
+	parent, ok := s.index.Lookup(paths.Parent(p))
+	if !ok {
+		return err
+	}
+
+	properties, perr := s.properties(parent)
+	if perr != nil {
+		return perr
+	}
+
+	for _, property := range properties {
+		if paths.Escape(property.Name) == paths.Name(p) {
+			s.printProperty(property)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%v: no such node or property", p)
+}
+
+func (s *Shell) printProperty(p transform.Record) {
+	fmt.Fprintf(s.out, "%v %v\n", p.Type, p.Name)
+	for _, v := range p.Values {
+		if v.Binary {
+			fmt.Fprintf(s.out, "  <binary, %v bytes>\n", hex.DecodedLen(len(v.Data)))
+		} else {
+			fmt.Fprintf(s.out, "  %q\n", v.Data)
+		}
+	}
+}
+
+func (s *Shell) cd(args []string) error {
+	if len(args) == 0 {
+		s.cwd = "/"
+		return nil
+	}
+	e, err := s.lookup(args)
+	if err != nil {
+		return err
+	}
+	s.cwd = e.Path
+	return nil
+}
+
+func (s *Shell) du(args []string) error {
+	e, err := s.lookup(args)
+	if err != nil {
+		return err
+	}
+	for _, child := range s.index.Children(e) {
+		fmt.Fprintf(s.out, "%v\t%v\n", child.Length, paths.Name(child.Path))
+	}
+	fmt.Fprintf(s.out, "%v\t.\n", e.Length)
+	return nil
+}
+
+func (s *Shell) exit(args []string) error {
+	return ErrExit
+}
+
+func (s *Shell) find(args []string) error {
+	if len(args) > 2 {
+		return errors.New("too many arguments")
+	}
+
+	pattern := "*"
+	if len(args) == 2 {
+		pattern = args[1]
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return err
+	}
+
+	e, err := s.lookup(args[:min(len(args), 1)])
+	if err != nil {
+		return err
+	}
+
+	for _, d := range s.descendants(e) {
+		if ok, _ := path.Match(pattern, paths.Name(d.Path)); ok {
+			fmt.Fprintln(s.out, d.Path)
+		}
+	}
+
+	return nil
+}
+
+func (s *Shell) descendants(e index.Entry) []index.Entry {
+	var result []index.Entry
+	for _, child := range s.index.Children(e) {
+		result = append(result, child)
+		result = append(result, s.descendants(child)...)
+	}
+	return result
+}
+
+func (s *Shell) help(args []string) error {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(s.out, "%-22v %v\n", commands[name].usage, commands[name].help)
+	}
+	return nil
+}
+
+func (s *Shell) printHistory(args []string) error {
+	for i, line := range s.history {
+		fmt.Fprintf(s.out, "%5d  %v\n", i+1, line)
+	}
+	return nil
+}
+
+func (s *Shell) ls(args []string) error {
+	e, err := s.lookup(args)
+	if err != nil {
+		return err
+	}
+	for _, child := range s.index.Children(e) {
+		fmt.Fprintf(s.out, "%v/\n", paths.Name(child.Path))
+	}
+	properties, err := s.properties(e)
+	if err != nil {
+		return err
+	}
+	for _, p := range properties {
+		fmt.Fprintln(s.out, p.Name)
+	}
+	return nil
+}
+
+func (s *Shell) pwd(args []string) error {
+	fmt.Fprintln(s.out, s.cwd)
+	return nil
+}
+
+func (s *Shell) stat(args []string) error {
+	e, err := s.lookup(args)
+	if err != nil {
+		return err
+	}
+	properties, err := s.properties(e)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "Path: %v\n", e.Path)
+	fmt.Fprintf(s.out, "Offset: %v\n", e.Offset)
+	fmt.Fprintf(s.out, "Size: %v bytes\n", e.Length)
+	fmt.Fprintf(s.out, "Children: %v\n", len(s.index.Children(e)))
+	fmt.Fprintf(s.out, "Descendants: %v\n", e.Descendants)
+	fmt.Fprintf(s.out, "Properties: %v\n", len(properties))
+	return nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
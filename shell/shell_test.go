@@ -0,0 +1,122 @@
+package shell
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/index"
+)
+
+const export = `r
+p name jcr:primaryType
+v rep:root
+^
+c content
+p string title
+v Hello
+v World
+^
+c page one
+p binary data
+x cafe
+^
+^
+c page two
+^
+^
+c apps
+^
+^
+`
+
+func newShell(t *testing.T) (*Shell, *strings.Builder) {
+	t.Helper()
+	ix, err := index.Build(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("build index: %v\n", err)
+	}
+	var out strings.Builder
+	return New(ix, strings.NewReader(export), &out), &out
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		lines    []string
+		expected string
+	}{
+		{[]string{"pwd"}, "/\n"},
+		{[]string{"cd content", "pwd"}, "/content\n"},
+		{[]string{"cd /content/page\\ one", "cd ..", "pwd"}, "/content\n"},
+		{[]string{"cd content", "cd", "pwd"}, "/\n"},
+		{[]string{"ls"}, "content/\napps/\njcr:primaryType\n"},
+		{[]string{"ls content"}, "page one/\npage two/\ntitle\n"},
+		{[]string{"cat content"}, "string title\n  \"Hello\"\n  \"World\"\n"},
+		{[]string{"cat '/content/page one/data'"}, "binary data\n  <binary, 2 bytes>\n"},
+		{[]string{"find / 'page*'"}, "/content/page one\n/content/page two\n"},
+		{[]string{"find content"}, "/content/page one\n/content/page two\n"},
+		{[]string{"du content"}, "36\tpage one\n13\tpage two\n94\t.\n"},
+		{[]string{"stat /content"}, "Path: /content\nOffset: 38\nSize: 94 bytes\nChildren: 2\nDescendants: 2\nProperties: 1\n"},
+		{[]string{"pwd", "", "history"}, "/\n    1  pwd\n    2  history\n"},
+	}
+
+	for _, tt := range tests {
+		s, out := newShell(t)
+		for _, line := range tt.lines {
+			if err := s.Execute(line); err != nil {
+				t.Errorf("executing %v: %v\n", line, err)
+			}
+		}
+		if out.String() != tt.expected {
+			t.Errorf("executing %v: expected %q, got %q\n", tt.lines, tt.expected, out.String())
+		}
+	}
+}
+
+func TestExecuteErrors(t *testing.T) {
+	tests := []string{
+		"unknown",
+		"cd /missing",
+		"cd a b",
+		"ls /missing",
+		"cat /content/missing",
+		"find / [",
+		"cd 'unterminated",
+	}
+
+	for _, tt := range tests {
+		s, _ := newShell(t)
+		if err := s.Execute(tt); err == nil {
+			t.Errorf("executing %v: expected error\n", tt)
+		}
+	}
+}
+
+func TestExecuteExit(t *testing.T) {
+	s, _ := newShell(t)
+	if err := s.Execute("exit"); err != ErrExit {
+		t.Fatalf("expected ErrExit, got %v\n", err)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{"c", []string{"cat ", "cd "}},
+		{"hi", []string{"history "}},
+		{"ls ", []string{"ls content/", "ls apps/"}},
+		{"ls c", []string{"ls content/"}},
+		{"ls content/p", []string{`ls content/page\ one/`, `ls content/page\ two/`}},
+		{`ls content/page\ o`, []string{`ls content/page\ one/`}},
+		{"ls /missing/", nil},
+	}
+
+	for _, tt := range tests {
+		s, _ := newShell(t)
+		completions := s.Complete(tt.line)
+		if strings.Join(completions, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("completing %q: expected %q, got %q\n", tt.line, tt.expected, completions)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package shell
+
+import "errors"
+
+// makeRaw is not supported on this platform. The shell falls back to reading
+// plain lines, without history and completion.
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("raw mode not supported")
+}
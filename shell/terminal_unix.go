@@ -0,0 +1,41 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package shell
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// makeRaw puts the terminal referred to by fd in raw mode, and returns a
+// function restoring its previous state.
+func makeRaw(fd int) (func(), error) {
+	var old syscall.Termios
+
+	if err := ioctlTermios(fd, ioctlGetTermios, &old); err != nil {
+		return nil, err
+	}
+
+	raw := old
+	raw.Iflag &^= syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+
+	if err := ioctlTermios(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		ioctlTermios(fd, ioctlSetTermios, &old)
+	}, nil
+}
+
+func ioctlTermios(fd int, request uintptr, t *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), request, uintptr(unsafe.Pointer(t)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
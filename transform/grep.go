@@ -0,0 +1,101 @@
+package transform
+
+import (
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/paths"
+)
+
+// GrepOptions controls the behaviour of Grep.
+type GrepOptions struct {
+	// Pattern is the regular expression matched against the values.
+	Pattern *regexp.Regexp
+	// Name, if not empty, is a glob pattern restricting the search to the
+	// properties whose name matches it.
+	Name string
+	// Binary controls whether binary values are searched. The pattern is
+	// matched against the decoded payload of binary values.
+	Binary bool
+}
+
+// Match describes a match of a pattern in a value, or an error if the
+// transformation fails.
+type Match struct {
+	// Path is the fully qualified path of the property.
+	Path string
+	// Index is the index of the matching value in the property.
+	Index int
+	// Match is the matching text.
+	Match string
+	// Binary is true if the matching value is a binary value.
+	Binary bool
+
+	Err  error
+	Line int
+}
+
+// Grep transforms a stream of commands into a stream of matches, one for every
+// match of a pattern in the values of the properties. A value can contain
+// more than one match. If the name pattern in options is invalid, Grep emits a
+// single error.
+func Grep(cmds <-chan parser.Cmd, options GrepOptions) <-chan Match {
+	results := make(chan Match)
+
+	go func() {
+		defer close(results)
+
+		if _, err := path.Match(options.Name, ""); err != nil {
+			results <- Match{Err: err}
+			return
+		}
+
+		for r := range Records(cmds) {
+			if r.Err != nil {
+				results <- Match{Err: r.Err, Line: r.Line}
+				continue
+			}
+			if r.Kind != PropertyRecord {
+				continue
+			}
+			if options.Name != "" {
+				if ok, _ := path.Match(options.Name, r.Name); !ok {
+					continue
+				}
+			}
+			if err := grepProperty(r, options, results); err != nil {
+				results <- Match{Err: err}
+			}
+		}
+	}()
+
+	return results
+}
+
+func grepProperty(r Record, options GrepOptions, results chan<- Match) error {
+	p := paths.Join(r.Path, paths.Escape(r.Name))
+
+	for i, v := range r.Values {
+		data := v.Data
+
+		if v.Binary {
+			if !options.Binary {
+				continue
+			}
+			decoded, err := hex.DecodeString(v.Data)
+			if err != nil {
+				return fmt.Errorf("%v: decoding value %v: %v", p, i, err)
+			}
+			data = string(decoded)
+		}
+
+		for _, m := range options.Pattern.FindAllString(data, -1) {
+			results <- Match{Path: p, Index: i, Match: m, Binary: v.Binary}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package transform
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+func TestGrep(t *testing.T) {
+	export := `
+		r
+		p String host
+		v old.example.com
+		^
+		c a
+		p String hosts
+		v new.example.com
+		v old.example.com and old.example.org
+		^
+		p Binary data
+		x 6f6c642e6578616d706c652e6e6574
+		^
+		^
+		^
+	`
+
+	tests := []struct {
+		name    string
+		options GrepOptions
+		matches []Match
+	}{
+		{
+			"text values",
+			GrepOptions{Pattern: regexp.MustCompile(`old\.example\.\w+`)},
+			[]Match{
+				{Path: "/host", Index: 0, Match: "old.example.com"},
+				{Path: "/a/hosts", Index: 1, Match: "old.example.com"},
+				{Path: "/a/hosts", Index: 1, Match: "old.example.org"},
+			},
+		},
+		{
+			"binary values",
+			GrepOptions{Pattern: regexp.MustCompile(`\.net`), Binary: true},
+			[]Match{
+				{Path: "/a/data", Index: 0, Match: ".net", Binary: true},
+			},
+		},
+		{
+			"name filter",
+			GrepOptions{Pattern: regexp.MustCompile(`(?i)EXAMPLE\.COM`), Name: "hosts"},
+			[]Match{
+				{Path: "/a/hosts", Index: 0, Match: "example.com"},
+				{Path: "/a/hosts", Index: 1, Match: "example.com"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		var matches []Match
+
+		for m := range Grep(parser.Parse(strings.NewReader(export)), tt.options) {
+			if m.Err != nil {
+				t.Fatalf("%v: error at line %v: %v\n", tt.name, m.Line, m.Err)
+			}
+			matches = append(matches, m)
+		}
+
+		if !reflect.DeepEqual(matches, tt.matches) {
+			t.Errorf("%v: expected %+v, got %+v\n", tt.name, tt.matches, matches)
+		}
+	}
+}
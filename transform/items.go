@@ -0,0 +1,116 @@
+package transform
+
+import (
+	"encoding/hex"
+
+	"github.com/francescomari/nu/parser"
+)
+
+// Item describes a node or a property with some aggregated information, or an
+// error if the transformation fails.
+type Item struct {
+	Kind RecordKind
+	// Path is the fully qualified path of the node or property. Same-name
+	// siblings after the first are addressed by their index, like in
+	// `/a/b[2]`.
+	Path string
+	// Name is the name of the node or property. The name of the root node is
+	// empty.
+	Name string
+	// Depth is the number of components in Path.
+	Depth int
+	// Size is the size of the values of a property, or the size of the values
+	// of every property in the subtree of a node. For values expressed by a V
+	// command, the size is the length of the value in bytes. For values
+	// expressed by a X command, the size is the length of the decoded payload.
+	Size int64
+	// Type is the type of a property. Type is empty for nodes.
+	Type string
+	// Values is the number of values of a property. Values is zero for nodes.
+	Values int
+	// Children is the number of children of a node. Children is zero for
+	// properties.
+	Children int
+	// Properties are the names of the properties of a node. Properties is
+	// empty for properties.
+	Properties []string
+
+	Err  error
+	Line int
+}
+
+// Items transforms a stream of commands into a stream of items, one for every
+// node and one for every property. Since an item contains information about
+// the whole subtree of the node, the item of a node is emitted when its
+// subtree ends, after the items of its properties and descendants.
+func Items(cmds <-chan parser.Cmd) <-chan Item {
+	results := make(chan Item)
+
+	go func() {
+		defer close(results)
+
+		var (
+			nodes    []*Item
+			path     pathTracker
+			property *Item
+		)
+
+		for cmd := range cmds {
+			path.update(cmd)
+
+			switch c := cmd.(type) {
+			case parser.Err:
+				results <- Item{Err: c.Err, Line: c.Line}
+			case parser.R:
+				nodes = append(nodes, &Item{Kind: NodeRecord, Path: "/"})
+			case parser.C:
+				if len(nodes) > 0 {
+					nodes[len(nodes)-1].Children++
+				}
+				nodes = append(nodes, &Item{
+					Kind:  NodeRecord,
+					Path:  path.path(),
+					Name:  c.Name,
+					Depth: path.depth(),
+				})
+			case parser.P:
+				property = &Item{
+					Kind:  PropertyRecord,
+					Path:  path.path(),
+					Name:  c.Name,
+					Depth: path.depth(),
+					Type:  c.Type,
+				}
+			case parser.V:
+				if property != nil {
+					property.Values++
+					property.Size += int64(len([]byte(c.Data)))
+				}
+			case parser.X:
+				if property != nil {
+					property.Values++
+					property.Size += int64(hex.DecodedLen(len(c.Data)))
+				}
+			case parser.Up:
+				if property != nil {
+					if len(nodes) > 0 {
+						node := nodes[len(nodes)-1]
+						node.Properties = append(node.Properties, property.Name)
+						node.Size += property.Size
+					}
+					results <- *property
+					property = nil
+				} else if len(nodes) > 0 {
+					node := nodes[len(nodes)-1]
+					nodes = nodes[:len(nodes)-1]
+					if len(nodes) > 0 {
+						nodes[len(nodes)-1].Size += node.Size
+					}
+					results <- *node
+				}
+			}
+		}
+	}()
+
+	return results
+}
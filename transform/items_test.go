@@ -0,0 +1,53 @@
+package transform
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+func TestItems(t *testing.T) {
+	cmds := parser.Parse(strings.NewReader(`
+		r
+		p x y
+		v ab
+		v c
+		^
+		c 1
+		p t u
+		x cafe
+		^
+		c 1.1
+		^
+		^
+		^
+	`))
+
+	var items []Item
+
+	for i := range Items(cmds) {
+		if i.Err != nil {
+			t.Fatalf("error at line %v: %v\n", i.Line, i.Err)
+		}
+		items = append(items, i)
+	}
+
+	expected := []Item{
+		{Kind: PropertyRecord, Path: "/y", Name: "y", Depth: 1, Size: 3, Type: "x", Values: 2},
+		{Kind: PropertyRecord, Path: "/1/u", Name: "u", Depth: 2, Size: 2, Type: "t", Values: 1},
+		{Kind: NodeRecord, Path: "/1/1.1", Name: "1.1", Depth: 2},
+		{Kind: NodeRecord, Path: "/1", Name: "1", Depth: 1, Size: 2, Children: 1, Properties: []string{"u"}},
+		{Kind: NodeRecord, Path: "/", Depth: 0, Size: 5, Children: 1, Properties: []string{"y"}},
+	}
+
+	if len(items) != len(expected) {
+		t.Fatalf("expected %v items, got %v\n", len(expected), len(items))
+	}
+	for i, item := range expected {
+		if !reflect.DeepEqual(item, items[i]) {
+			t.Errorf("expected %+v, got %+v\n", item, items[i])
+		}
+	}
+}
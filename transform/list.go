@@ -0,0 +1,126 @@
+package transform
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/paths"
+)
+
+var (
+	// ErrNodeNotFound is returned when a node doesn't exist in an export.
+	ErrNodeNotFound = errors.New("node not found")
+)
+
+// ListNode describes a child of a listed node.
+type ListNode struct {
+	Name string
+	// Children is the number of children of the node.
+	Children int
+	// Properties is the number of properties of the node.
+	Properties int
+}
+
+// ListProperty describes a property of a listed node.
+type ListProperty struct {
+	Name string
+	Type string
+	// Values is the number of values of the property.
+	Values int
+	// Size is the total size of the values of the property. For values
+	// expressed by a V command, the size is the length of the value in bytes.
+	// For values expressed by a X command, the size is the length of the
+	// decoded payload.
+	Size int64
+}
+
+// Listing contains the children and the properties of a node.
+type Listing struct {
+	Nodes      []ListNode
+	Properties []ListProperty
+}
+
+// List reads a stream of commands and lists the children and the properties of
+// the node at path. List returns as soon as the subtree of the node ends,
+// without reading the rest of the stream. If the node doesn't exist, List
+// returns ErrNodeNotFound. The path can address same-name siblings, like in
+// `/a/b[2]`.
+func List(path string, commands <-chan parser.Cmd) (*Listing, error) {
+	target, err := paths.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("splitting path components: %v", err)
+	}
+
+	var (
+		listing    Listing
+		nodes      paths.Path
+		siblings   paths.Siblings
+		found      bool
+		inProperty bool
+		property   *ListProperty
+	)
+
+	for command := range commands {
+		switch cmd := command.(type) {
+		case parser.R:
+			siblings.Enter("")
+			found = target.IsRoot()
+		case parser.C:
+			nodes = append(nodes, paths.Segment{Name: cmd.Name, Index: siblings.Enter(cmd.Name)})
+			switch {
+			case !found:
+				found = nodes.Equal(target)
+			case len(nodes) == len(target)+1:
+				listing.Nodes = append(listing.Nodes, ListNode{Name: cmd.Name})
+			case len(nodes) == len(target)+2:
+				listing.Nodes[len(listing.Nodes)-1].Children++
+			}
+		case parser.P:
+			siblings.EnterProperty()
+			inProperty = true
+			switch {
+			case !found:
+			case len(nodes) == len(target):
+				listing.Properties = append(listing.Properties, ListProperty{Name: cmd.Name, Type: cmd.Type})
+				property = &listing.Properties[len(listing.Properties)-1]
+			case len(nodes) == len(target)+1:
+				listing.Nodes[len(listing.Nodes)-1].Properties++
+			}
+		case parser.V:
+			if property != nil {
+				property.Values++
+				property.Size += int64(len([]byte(cmd.Data)))
+			}
+		case parser.X:
+			if property != nil {
+				property.Values++
+				property.Size += int64(hex.DecodedLen(len(cmd.Data)))
+			}
+		case parser.Up:
+			siblings.Leave()
+			if inProperty {
+				inProperty = false
+				property = nil
+				continue
+			}
+			if found && len(nodes) == len(target) {
+				return &listing, nil
+			}
+			if len(nodes) > 0 {
+				nodes = nodes[:len(nodes)-1]
+			}
+		case parser.Err:
+			return nil, fmt.Errorf("error at line %v: %v", cmd.Line, cmd.Err)
+		default:
+			return nil, fmt.Errorf("unexpected command %T", cmd)
+		}
+	}
+
+	if !found {
+		return nil, ErrNodeNotFound
+	}
+
+	return &listing, nil
+}
@@ -0,0 +1,124 @@
+package transform
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+const listExport = `
+	r
+	p name jcr:primaryType
+	v rep:root
+	^
+	c a
+	p string t
+	v hello
+	v world
+	^
+	c a1
+	p binary b
+	x cafe
+	^
+	c a11
+	^
+	^
+	c a2
+	^
+	^
+	c b
+	^
+	^
+`
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected Listing
+	}{
+		{
+			"/",
+			Listing{
+				Nodes: []ListNode{
+					{Name: "a", Children: 2, Properties: 1},
+					{Name: "b"},
+				},
+				Properties: []ListProperty{
+					{Name: "jcr:primaryType", Type: "name", Values: 1, Size: 8},
+				},
+			},
+		},
+		{
+			"/a",
+			Listing{
+				Nodes: []ListNode{
+					{Name: "a1", Children: 1, Properties: 1},
+					{Name: "a2"},
+				},
+				Properties: []ListProperty{
+					{Name: "t", Type: "string", Values: 2, Size: 10},
+				},
+			},
+		},
+		{
+			"/a/a1",
+			Listing{
+				Nodes: []ListNode{
+					{Name: "a11"},
+				},
+				Properties: []ListProperty{
+					{Name: "b", Type: "binary", Values: 1, Size: 2},
+				},
+			},
+		},
+		{
+			"/b",
+			Listing{},
+		},
+	}
+
+	for _, tt := range tests {
+		listing, err := List(tt.path, parser.Parse(strings.NewReader(listExport)))
+		if err != nil {
+			t.Errorf("path %v: %v\n", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(*listing, tt.expected) {
+			t.Errorf("path %v: expected %+v, got %+v\n", tt.path, tt.expected, *listing)
+		}
+	}
+}
+
+func TestListNotFound(t *testing.T) {
+	for _, path := range []string{"/c", "/a/b", "/a/a1/a11/x"} {
+		if _, err := List(path, parser.Parse(strings.NewReader(listExport))); err != ErrNodeNotFound {
+			t.Errorf("path %v: expected ErrNodeNotFound, got %v\n", path, err)
+		}
+	}
+}
+
+func TestListSameNameSiblings(t *testing.T) {
+	export := `
+		r
+		c a
+		c x
+		^
+		^
+		c a
+		c y
+		^
+		^
+		^
+	`
+
+	listing, err := List("/a[2]", parser.Parse(strings.NewReader(export)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	if expected := (Listing{Nodes: []ListNode{{Name: "y"}}}); !reflect.DeepEqual(*listing, expected) {
+		t.Errorf("expected %+v, got %+v\n", expected, *listing)
+	}
+}
@@ -0,0 +1,83 @@
+package transform
+
+import (
+	"fmt"
+
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/paths"
+)
+
+// LookupProperties reads a stream of commands and returns the properties of the
+// node at path. If there is no node at path, but path is the path of a
+// property, LookupProperties returns only that property. LookupProperties
+// returns as soon as the subtree containing the node or the property ends,
+// without reading the rest of the stream. If neither a node nor a property
+// exist at path, LookupProperties returns ErrNodeNotFound. The path can
+// address same-name siblings, like in `/a/b[2]`.
+func LookupProperties(path string, commands <-chan parser.Cmd) ([]Record, error) {
+	p, err := paths.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("parsing path: %v", err)
+	}
+
+	var (
+		nodePath   = p.String()
+		parentPath string
+		name       string
+		// isProperty is false if path can't address a property. Properties
+		// don't have same-name siblings, so the last segment of the path of a
+		// property has no index.
+		isProperty bool
+	)
+
+	if !p.IsRoot() {
+		parentPath = p.Parent().String()
+		name = p.Name()
+		isProperty = p[len(p)-1].Index == 1
+	}
+
+	var (
+		nodeFound    bool
+		parentFound  bool
+		nodeProps    []Record
+		matchingProp []Record
+	)
+
+	for r := range Records(commands) {
+		if r.Err != nil {
+			return nil, fmt.Errorf("error at line %v: %v", r.Line, r.Err)
+		}
+
+		switch r.Kind {
+		case NodeRecord:
+			if parentFound && !paths.Contains(parentPath, r.Path) {
+				return lookupResult(nodeFound, nodeProps, matchingProp)
+			}
+			if r.Path == nodePath {
+				nodeFound = true
+			}
+			if r.Path == parentPath {
+				parentFound = true
+			}
+		case PropertyRecord:
+			if r.Path == nodePath {
+				nodeProps = append(nodeProps, r)
+			}
+			if isProperty && r.Path == parentPath && r.Name == name {
+				matchingProp = append(matchingProp, r)
+			}
+		}
+	}
+
+	return lookupResult(nodeFound, nodeProps, matchingProp)
+}
+
+func lookupResult(nodeFound bool, nodeProps, matchingProp []Record) ([]Record, error) {
+	if nodeFound {
+		return nodeProps, nil
+	}
+	if len(matchingProp) > 0 {
+		return matchingProp, nil
+	}
+	return nil, ErrNodeNotFound
+}
@@ -0,0 +1,106 @@
+package transform
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+func TestLookupProperties(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected []Record
+	}{
+		{
+			"/",
+			[]Record{
+				{Kind: PropertyRecord, Path: "/", Name: "jcr:primaryType", Type: "name", Values: []Value{{Data: "rep:root"}}},
+			},
+		},
+		{
+			"/a",
+			[]Record{
+				{Kind: PropertyRecord, Path: "/a", Name: "t", Type: "string", Values: []Value{{Data: "hello"}, {Data: "world"}}},
+			},
+		},
+		{
+			"/a/t",
+			[]Record{
+				{Kind: PropertyRecord, Path: "/a", Name: "t", Type: "string", Values: []Value{{Data: "hello"}, {Data: "world"}}},
+			},
+		},
+		{
+			"/a/a1/b",
+			[]Record{
+				{Kind: PropertyRecord, Path: "/a/a1", Name: "b", Type: "binary", Values: []Value{{Data: "cafe", Binary: true}}},
+			},
+		},
+		{
+			"/b",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		properties, err := LookupProperties(tt.path, parser.Parse(strings.NewReader(listExport)))
+		if err != nil {
+			t.Errorf("path %v: %v\n", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(properties, tt.expected) {
+			t.Errorf("path %v: expected %+v, got %+v\n", tt.path, tt.expected, properties)
+		}
+	}
+}
+
+func TestLookupPropertiesNotFound(t *testing.T) {
+	for _, path := range []string{"/c", "/a/x", "/a/t/x"} {
+		if _, err := LookupProperties(path, parser.Parse(strings.NewReader(listExport))); err != ErrNodeNotFound {
+			t.Errorf("path %v: expected ErrNodeNotFound, got %v\n", path, err)
+		}
+	}
+}
+
+func TestLookupPropertiesSameNameSiblings(t *testing.T) {
+	export := `
+		r
+		c a
+		p String x
+		v 1
+		^
+		^
+		c a
+		p String x
+		v 2
+		^
+		^
+		^
+	`
+
+	tests := []struct {
+		path     string
+		expected []Record
+	}{
+		{"/a", []Record{{Kind: PropertyRecord, Path: "/a", Name: "x", Type: "String", Values: []Value{{Data: "1"}}}}},
+		{"/a[1]/x", []Record{{Kind: PropertyRecord, Path: "/a", Name: "x", Type: "String", Values: []Value{{Data: "1"}}}}},
+		{"/a[2]", []Record{{Kind: PropertyRecord, Path: "/a[2]", Name: "x", Type: "String", Values: []Value{{Data: "2"}}}}},
+		{"/a[2]/x", []Record{{Kind: PropertyRecord, Path: "/a[2]", Name: "x", Type: "String", Values: []Value{{Data: "2"}}}}},
+	}
+
+	for _, tt := range tests {
+		properties, err := LookupProperties(tt.path, parser.Parse(strings.NewReader(export)))
+		if err != nil {
+			t.Errorf("path %v: %v\n", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(properties, tt.expected) {
+			t.Errorf("path %v: expected %+v, got %+v\n", tt.path, tt.expected, properties)
+		}
+	}
+
+	if _, err := LookupProperties("/a[3]", parser.Parse(strings.NewReader(export))); err != ErrNodeNotFound {
+		t.Errorf("path /a[3]: expected ErrNodeNotFound, got %v\n", err)
+	}
+}
@@ -1,8 +1,6 @@
 package transform
 
 import (
-	"strings"
-
 	"github.com/francescomari/nu/parser"
 )
 
@@ -16,30 +14,24 @@ type NodePath struct {
 }
 
 // Nodes transform a stream of commands into a stream of fully qualified node
-// paths.
+// paths. Every node after the first among its same-name siblings is addressed
+// by its index, like in `/a/b[2]`.
 func Nodes(cmds <-chan parser.Cmd) <-chan NodePath {
 	results := make(chan NodePath)
 
 	go func() {
 		defer close(results)
 
-		var path []string
+		var path pathTracker
 
 		for cmd := range cmds {
+			path.update(cmd)
+
 			switch c := cmd.(type) {
 			case parser.Err:
 				results <- NodePath{Err: c.Err, Line: c.Line}
-				break
-			case parser.R:
-				path = append(path, "")
-				results <- NodePath{Path: "/"}
-			case parser.C:
-				path = append(path, c.Name)
-				results <- NodePath{Path: strings.Join(path, "/")}
-			case parser.P:
-				path = append(path, c.Name)
-			case parser.Up:
-				path = path[:len(path)-1]
+			case parser.R, parser.C:
+				results <- NodePath{Path: path.path()}
 			}
 		}
 	}()
@@ -50,3 +50,94 @@ func TestNodes(t *testing.T) {
 		}
 	}
 }
+
+func TestNodesEscaped(t *testing.T) {
+	cmds := parser.Parse(strings.NewReader(`
+		r
+		c a/b
+		c ..
+		^
+		^
+		c 100%
+		^
+		^
+	`))
+
+	var paths []string
+
+	for p := range Nodes(cmds) {
+		if p.Err != nil {
+			t.Fatalf("error at line %v: %v\n", p.Line, p.Err)
+		}
+		paths = append(paths, p.Path)
+	}
+
+	expected := []string{
+		"/",
+		"/a%2Fb",
+		"/a%2Fb/%2E%2E",
+		"/100%25",
+	}
+
+	if len(paths) != len(expected) {
+		t.Fatalf("expected %v paths, got %v\n", len(expected), len(paths))
+	}
+	for i, p := range expected {
+		if p != paths[i] {
+			t.Errorf("expected %v, got %v\n", p, paths[i])
+		}
+	}
+}
+
+func TestNodesSameNameSiblings(t *testing.T) {
+	cmds := parser.Parse(strings.NewReader(`
+		r
+		c a
+		c b
+		^
+		p t b
+		v x
+		^
+		c b
+		c c
+		^
+		^
+		c c
+		^
+		c b
+		^
+		^
+		c a
+		^
+		^
+	`))
+
+	var paths []string
+
+	for p := range Nodes(cmds) {
+		if p.Err != nil {
+			t.Fatalf("error at line %v: %v\n", p.Line, p.Err)
+		}
+		paths = append(paths, p.Path)
+	}
+
+	expected := []string{
+		"/",
+		"/a",
+		"/a/b",
+		"/a/b[2]",
+		"/a/b[2]/c",
+		"/a/c",
+		"/a/b[3]",
+		"/a[2]",
+	}
+
+	if len(paths) != len(expected) {
+		t.Fatalf("expected %v paths, got %v\n", len(expected), len(paths))
+	}
+	for i, p := range expected {
+		if p != paths[i] {
+			t.Errorf("expected %v, got %v\n", p, paths[i])
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package transform
+
+import (
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/paths"
+)
+
+// pathTracker tracks the path of the node or property opened by the last
+// command read from a stream. Same-name siblings are addressed by their
+// index, so that every path addresses a single node. It is shared by the
+// transformations that need the path of every node or property.
+type pathTracker struct {
+	// current are the segments of the open nodes and property, excluding the
+	// root.
+	current  paths.Path
+	siblings paths.Siblings
+}
+
+// update updates the path with a command read from the stream.
+func (t *pathTracker) update(cmd parser.Cmd) {
+	switch c := cmd.(type) {
+	case parser.R:
+		t.siblings.Enter("")
+	case parser.C:
+		t.current = append(t.current, paths.Segment{Name: c.Name, Index: t.siblings.Enter(c.Name)})
+	case parser.P:
+		t.siblings.EnterProperty()
+		t.current = append(t.current, paths.Segment{Name: c.Name, Index: 1})
+	case parser.Up:
+		t.siblings.Leave()
+		if len(t.current) > 0 {
+			t.current = t.current[:len(t.current)-1]
+		}
+	}
+}
+
+// path returns the escaped path of the current node or property.
+func (t *pathTracker) path() string {
+	return t.current.String()
+}
+
+// parentPath returns the escaped path of the parent of the current node or
+// property.
+func (t *pathTracker) parentPath() string {
+	return t.current.Parent().String()
+}
+
+// depth returns the number of components in the path of the current node or
+// property.
+func (t *pathTracker) depth() int {
+	return t.current.Depth()
+}
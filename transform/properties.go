@@ -1,8 +1,6 @@
 package transform
 
 import (
-	"strings"
-
 	"github.com/francescomari/nu/parser"
 )
 
@@ -24,22 +22,16 @@ func Properties(cmds <-chan parser.Cmd) <-chan PropertyPath {
 	go func() {
 		defer close(results)
 
-		var path []string
+		var path pathTracker
 
 		for cmd := range cmds {
+			path.update(cmd)
+
 			switch c := cmd.(type) {
 			case parser.Err:
 				results <- PropertyPath{Err: c.Err, Line: c.Line}
-				break
-			case parser.R:
-				path = append(path, "")
-			case parser.C:
-				path = append(path, c.Name)
 			case parser.P:
-				path = append(path, c.Name)
-				results <- PropertyPath{Path: strings.Join(path, "/"), Type: c.Type}
-			case parser.Up:
-				path = path[:len(path)-1]
+				results <- PropertyPath{Path: path.path(), Type: c.Type}
 			}
 		}
 	}()
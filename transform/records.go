@@ -0,0 +1,91 @@
+package transform
+
+import (
+	"github.com/francescomari/nu/parser"
+)
+
+// RecordKind is the kind of the item described by a Record.
+type RecordKind int
+
+const (
+	// NodeRecord is the kind of a Record describing a node.
+	NodeRecord RecordKind = iota
+	// PropertyRecord is the kind of a Record describing a property.
+	PropertyRecord
+)
+
+// Value is a value of a property.
+type Value struct {
+	// Data is the data of the value, as read from a V or X command.
+	Data string
+	// Binary is true if the value was read from a X command.
+	Binary bool
+}
+
+// Record describes a node or a property, or an error if the transformation
+// fails.
+type Record struct {
+	Kind RecordKind
+	// Path is the fully qualified path of the node. For a property, Path is
+	// the fully qualified path of the node the property is attached to.
+	// Same-name siblings after the first are addressed by their index, like
+	// in `/a/b[2]`.
+	Path string
+	// Name is the name of the node or property. The name of the root node is
+	// empty.
+	Name string
+	// Type is the type of the property. Type is empty for nodes.
+	Type string
+	// Values are the values of the property. Values is empty for nodes.
+	Values []Value
+
+	Err  error
+	Line int
+}
+
+// Records transforms a stream of commands into a stream of records, one for
+// every node and one for every property. A node record is emitted as soon as
+// the node is read. A property record is emitted when every value of the
+// property has been read.
+func Records(cmds <-chan parser.Cmd) <-chan Record {
+	results := make(chan Record)
+
+	go func() {
+		defer close(results)
+
+		var (
+			path     pathTracker
+			property *Record
+		)
+
+		for cmd := range cmds {
+			path.update(cmd)
+
+			switch c := cmd.(type) {
+			case parser.Err:
+				results <- Record{Err: c.Err, Line: c.Line}
+			case parser.R:
+				results <- Record{Kind: NodeRecord, Path: "/"}
+			case parser.C:
+				results <- Record{Kind: NodeRecord, Path: path.path(), Name: c.Name}
+			case parser.P:
+				property = &Record{Kind: PropertyRecord, Path: path.parentPath(), Name: c.Name, Type: c.Type}
+			case parser.V:
+				if property != nil {
+					property.Values = append(property.Values, Value{Data: c.Data})
+				}
+			case parser.X:
+				if property != nil {
+					property.Values = append(property.Values, Value{Data: c.Data, Binary: true})
+				}
+			case parser.Up:
+				if property != nil {
+					results <- *property
+					property = nil
+				}
+			}
+		}
+	}()
+
+	return results
+}
@@ -0,0 +1,56 @@
+package transform
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+func TestRecords(t *testing.T) {
+	cmds := parser.Parse(strings.NewReader(`
+		r
+		p x y
+		v a
+		v b
+		^
+		c 1
+		p t u
+		x cafe
+		^
+		c 1.1
+		p v w
+		^
+		^
+		^
+		^
+	`))
+
+	var records []Record
+
+	for r := range Records(cmds) {
+		if r.Err != nil {
+			t.Fatalf("error at line %v: %v\n", r.Line, r.Err)
+		}
+		records = append(records, r)
+	}
+
+	expected := []Record{
+		{Kind: NodeRecord, Path: "/"},
+		{Kind: PropertyRecord, Path: "/", Name: "y", Type: "x", Values: []Value{{Data: "a"}, {Data: "b"}}},
+		{Kind: NodeRecord, Path: "/1", Name: "1"},
+		{Kind: PropertyRecord, Path: "/1", Name: "u", Type: "t", Values: []Value{{Data: "cafe", Binary: true}}},
+		{Kind: NodeRecord, Path: "/1/1.1", Name: "1.1"},
+		{Kind: PropertyRecord, Path: "/1/1.1", Name: "w", Type: "v"},
+	}
+
+	if len(records) != len(expected) {
+		t.Fatalf("expected %v records, got %v\n", len(expected), len(records))
+	}
+	for i, r := range expected {
+		if !reflect.DeepEqual(r, records[i]) {
+			t.Errorf("expected %v, got %v\n", r, records[i])
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package transform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/paths"
+)
+
+const (
+	refsUUIDProperty = "jcr:uuid"
+)
+
+// Reference is a value of a Reference, WeakReference, or Path property.
+type Reference struct {
+	// Source is the fully qualified path of the property.
+	Source string
+	// Type is the type of the property.
+	Type string
+	// Value is the value of the property, as read from the export.
+	Value string
+	// Target is the fully qualified path of the referenced node, or empty if
+	// the referenced node doesn't exist.
+	Target string
+}
+
+// Target is a referenced node.
+type Target struct {
+	// Path is the fully qualified path of the node.
+	Path string
+	// References is the number of references to the node.
+	References int
+}
+
+// Refs describes the references between the nodes of an export.
+type Refs struct {
+	// References are the references in the export, in the order they are
+	// read.
+	References []Reference
+	// UUIDs maps the jcr:uuid of every referenceable node to its path.
+	UUIDs map[string]string
+
+	nodes map[string]bool
+}
+
+// CollectRefs reads a stream of commands and collects the references between
+// its nodes. Values of Reference and WeakReference properties are resolved
+// through the jcr:uuid property of the nodes. Values of Path properties are
+// resolved as paths, relative to the node of the property if they are not
+// absolute. Only paths of nodes are resolved. CollectRefs either returns a
+// non-nil Refs or an error.
+func CollectRefs(commands <-chan parser.Cmd) (*Refs, error) {
+	refs := Refs{
+		UUIDs: make(map[string]string),
+		nodes: make(map[string]bool),
+	}
+
+	for r := range Records(commands) {
+		if r.Err != nil {
+			return nil, fmt.Errorf("error at line %v: %v", r.Line, r.Err)
+		}
+
+		if r.Kind == NodeRecord {
+			refs.nodes[r.Path] = true
+			continue
+		}
+
+		if r.Name == refsUUIDProperty && len(r.Values) == 1 && !r.Values[0].Binary {
+			refs.UUIDs[r.Values[0].Data] = r.Path
+		}
+
+		if !isReferenceType(r.Type) && !isPathType(r.Type) {
+			continue
+		}
+
+		for _, v := range r.Values {
+			if v.Binary {
+				continue
+			}
+			refs.References = append(refs.References, Reference{
+				Source: paths.Join(r.Path, paths.Escape(r.Name)),
+				Type:   r.Type,
+				Value:  v.Data,
+			})
+		}
+	}
+
+	for i := range refs.References {
+		refs.References[i].Target = refs.resolve(refs.References[i])
+	}
+
+	return &refs, nil
+}
+
+func (r *Refs) resolve(ref Reference) string {
+	if isReferenceType(ref.Type) {
+		return r.UUIDs[ref.Value]
+	}
+
+	p, err := paths.Parse(paths.Parent(ref.Source))
+	if err != nil {
+		return ""
+	}
+
+	p, err = p.Resolve(paths.EscapePath(ref.Value))
+	if err != nil {
+		return ""
+	}
+
+	target := p.String()
+
+	if !r.nodes[target] {
+		return ""
+	}
+
+	return target
+}
+
+// Dangling returns the references whose target doesn't exist.
+func (r *Refs) Dangling() []Reference {
+	var result []Reference
+	for _, ref := range r.References {
+		if ref.Target == "" {
+			result = append(result, ref)
+		}
+	}
+	return result
+}
+
+// MostReferenced returns at most n targets, sorted by decreasing number of
+// references.
+func (r *Refs) MostReferenced(n int) []Target {
+	counts := make(map[string]int)
+	for _, ref := range r.References {
+		if ref.Target != "" {
+			counts[ref.Target]++
+		}
+	}
+
+	targets := make([]Target, 0, len(counts))
+	for p, count := range counts {
+		targets = append(targets, Target{p, count})
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].References != targets[j].References {
+			return targets[i].References > targets[j].References
+		}
+		return targets[i].Path < targets[j].Path
+	})
+
+	if len(targets) > n {
+		targets = targets[:n]
+	}
+
+	return targets
+}
+
+// Breaking returns the references that would become dangling if the subtree
+// at subtreePath were pruned. These are the references whose source is outside
+// the subtree and whose target is inside it.
+func (r *Refs) Breaking(subtreePath string) ([]Reference, error) {
+	subtree, err := paths.Parse(subtreePath)
+	if err != nil {
+		return nil, fmt.Errorf("parsing path: %v", err)
+	}
+
+	var result []Reference
+	for _, ref := range r.References {
+		if ref.Target == "" {
+			continue
+		}
+		source, err := paths.Parse(ref.Source)
+		if err != nil || subtree.Contains(source) {
+			continue
+		}
+		if target, err := paths.Parse(ref.Target); err == nil && subtree.Contains(target) {
+			result = append(result, ref)
+		}
+	}
+	return result, nil
+}
+
+func isReferenceType(t string) bool {
+	return strings.EqualFold(t, "Reference") || strings.EqualFold(t, "WeakReference")
+}
+
+func isPathType(t string) bool {
+	return strings.EqualFold(t, "Path")
+}
+
+// Closure returns the paths of the subtrees needed to extract the subtree at
+// subtreePath without breaking its references. The result contains subtreePath
+// and the targets of the references from the subtree, transitively, with the
+// exception of the paths already contained in another subtree of the result.
+// The result is sorted.
+func (r *Refs) Closure(subtreePath string) ([]string, error) {
+	p, err := paths.Parse(subtreePath)
+	if err != nil {
+		return nil, fmt.Errorf("parsing path: %v", err)
+	}
+
+	var (
+		// bySource are the references sorted by source, so that the
+		// references from a subtree are a contiguous range. Paths address
+		// same-name siblings by their index, so the references from `/a/b`
+		// don't include the ones from `/a/b[2]`.
+		bySource = r.sortedBySource()
+		subtree  = p.String()
+		roots    = map[string]bool{subtree: true}
+		queue    = []string{subtree}
+	)
+
+	for len(queue) > 0 {
+		root := queue[0]
+		queue = queue[1:]
+
+		prefix := root
+		if prefix != "/" {
+			prefix += "/"
+		}
+
+		i := sort.Search(len(bySource), func(i int) bool {
+			return bySource[i].Source >= prefix
+		})
+
+		for ; i < len(bySource) && strings.HasPrefix(bySource[i].Source, prefix); i++ {
+			target := bySource[i].Target
+			if target == "" || isInAnyRoot(target, roots) {
+				continue
+			}
+			roots[target] = true
+			queue = append(queue, target)
+		}
+	}
+
+	var result []string
+	for root := range roots {
+		if root == "/" || !isInAnyRoot(paths.Parent(root), roots) {
+			result = append(result, root)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result, nil
+}
+
+func (r *Refs) sortedBySource() []Reference {
+	sorted := make([]Reference, len(r.References))
+	copy(sorted, r.References)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Source < sorted[j].Source
+	})
+	return sorted
+}
+
+// isInAnyRoot tells if p, or any of its ancestors, is one of the roots.
+func isInAnyRoot(p string, roots map[string]bool) bool {
+	for {
+		if roots[p] {
+			return true
+		}
+		if p == "/" {
+			return false
+		}
+		p = paths.Parent(p)
+	}
+}
@@ -0,0 +1,219 @@
+package transform
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+const refsExport = `
+	r
+	c a
+	p String jcr:uuid
+	v 1111
+	^
+	c b
+	p String jcr:uuid
+	v 2222
+	^
+	^
+	^
+	c c
+	p Reference ref
+	v 1111
+	v 2222
+	^
+	p WeakReference weak
+	v 3333
+	^
+	p Path link
+	v ../a/b
+	^
+	p Path missing
+	v /x
+	^
+	^
+	c d
+	p Reference ref
+	v 2222
+	^
+	p Reference later
+	v 4444
+	^
+	^
+	c e
+	p String jcr:uuid
+	v 4444
+	^
+	^
+	^
+`
+
+func TestCollectRefs(t *testing.T) {
+	refs, err := CollectRefs(parser.Parse(strings.NewReader(refsExport)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	expected := []Reference{
+		{Source: "/c/ref", Type: "Reference", Value: "1111", Target: "/a"},
+		{Source: "/c/ref", Type: "Reference", Value: "2222", Target: "/a/b"},
+		{Source: "/c/weak", Type: "WeakReference", Value: "3333"},
+		{Source: "/c/link", Type: "Path", Value: "../a/b", Target: "/a/b"},
+		{Source: "/c/missing", Type: "Path", Value: "/x"},
+		{Source: "/d/ref", Type: "Reference", Value: "2222", Target: "/a/b"},
+		{Source: "/d/later", Type: "Reference", Value: "4444", Target: "/e"},
+	}
+
+	if !reflect.DeepEqual(refs.References, expected) {
+		t.Errorf("expected %+v, got %+v\n", expected, refs.References)
+	}
+
+	if dangling := refs.Dangling(); !reflect.DeepEqual(dangling, []Reference{expected[2], expected[4]}) {
+		t.Errorf("unexpected dangling references: %+v\n", dangling)
+	}
+
+	targets := []Target{{"/a/b", 3}, {"/a", 1}}
+	if most := refs.MostReferenced(2); !reflect.DeepEqual(most, targets) {
+		t.Errorf("expected most referenced %+v, got %+v\n", targets, most)
+	}
+
+	breaking, err := refs.Breaking("/a/b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	if expected := []Reference{expected[1], expected[3], expected[5]}; !reflect.DeepEqual(breaking, expected) {
+		t.Errorf("expected breaking %+v, got %+v\n", expected, breaking)
+	}
+
+	breaking, err = refs.Breaking("/a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	if len(breaking) != 4 {
+		t.Errorf("expected 4 breaking references, got %+v\n", breaking)
+	}
+}
+
+func TestRefsClosure(t *testing.T) {
+	refs, err := CollectRefs(parser.Parse(strings.NewReader(refsExport)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{"/a", []string{"/a"}},
+		{"/c", []string{"/a", "/c"}},
+		{"/d", []string{"/a/b", "/d", "/e"}},
+		{"/", []string{"/"}},
+	}
+
+	for _, tt := range tests {
+		closure, err := refs.Closure(tt.path)
+		if err != nil {
+			t.Errorf("path %v: unexpected error: %v\n", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(closure, tt.expected) {
+			t.Errorf("path %v: expected %v, got %v\n", tt.path, tt.expected, closure)
+		}
+	}
+}
+
+func TestRefsClosureSiblingPrefixes(t *testing.T) {
+	refs, err := CollectRefs(parser.Parse(strings.NewReader(`
+		r
+		c a
+		p Path link
+		v /x
+		^
+		^
+		c a-b
+		p Path link
+		v /y
+		^
+		^
+		c ab
+		p Path link
+		v /z
+		^
+		^
+		c x
+		p Path link
+		v /a/c
+		^
+		^
+		c y
+		^
+		c z
+		^
+		^
+	`)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	closure, err := refs.Closure("/a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	if expected := []string{"/a", "/x"}; !reflect.DeepEqual(closure, expected) {
+		t.Errorf("expected %v, got %v\n", expected, closure)
+	}
+}
+
+func TestRefsSameNameSiblings(t *testing.T) {
+	refs, err := CollectRefs(parser.Parse(strings.NewReader(`
+		r
+		c a
+		c b
+		p String jcr:uuid
+		v 1111
+		^
+		^
+		c b
+		p String jcr:uuid
+		v 2222
+		^
+		^
+		^
+		c x
+		p Reference ref
+		v 2222
+		^
+		p Path link
+		v ../a/b[2]
+		^
+		^
+		^
+	`)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	for _, ref := range refs.References {
+		if ref.Target != "/a/b[2]" {
+			t.Errorf("%v: expected target /a/b[2], got %v\n", ref.Source, ref.Target)
+		}
+	}
+
+	closure, err := refs.Closure("/x")
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	if expected := []string{"/a/b[2]", "/x"}; !reflect.DeepEqual(closure, expected) {
+		t.Errorf("closure: expected %v, got %v\n", expected, closure)
+	}
+
+	if breaking, err := refs.Breaking("/a/b"); err != nil || len(breaking) != 0 {
+		t.Errorf("breaking /a/b: expected no references, got %v, %v\n", breaking, err)
+	}
+	if breaking, err := refs.Breaking("/a/b[2]"); err != nil || len(breaking) != 2 {
+		t.Errorf("breaking /a/b[2]: expected 2 references, got %v, %v\n", breaking, err)
+	}
+}
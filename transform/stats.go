@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/paths"
 )
 
 const (
@@ -17,6 +18,14 @@ const (
 	// StatsValueSizeBucketScale is the scale of a bucket for the ValuesPerSize
 	// field in Stats.
 	StatsValueSizeBucketScale = 1024
+	// StatsTypeDepthBucketSize is the size of a bucket for the
+	// PrimaryTypesPerDepth field in Stats.
+	StatsTypeDepthBucketSize = 10
+)
+
+const (
+	statsPrimaryTypeProperty = "jcr:primaryType"
+	statsMixinTypesProperty  = "jcr:mixinTypes"
 )
 
 // Stats contains statistics about the data in an export.
@@ -46,16 +55,58 @@ type Stats struct {
 	// logarithmically. The base of the logarithimc increase is
 	// StatsValueSizeBucketScale.
 	ValuesPerSize map[int]int
+	// NodesPerPrimaryType is the number of nodes grouped by the value of their
+	// jcr:primaryType property. Nodes without a primary type are grouped
+	// under the empty string.
+	NodesPerPrimaryType map[string]int
+	// DataPerPrimaryType is the amount of data from the properties of the
+	// nodes, grouped like in NodesPerPrimaryType. Only the properties of a
+	// node are considered, not the properties of its descendants.
+	DataPerPrimaryType map[string]int64
+	// NodesPerMixin is the number of nodes grouped by the values of their
+	// jcr:mixinTypes property. A node with more than one mixin is counted
+	// once for every mixin.
+	NodesPerMixin map[string]int
+	// DataPerMixin is the amount of data from the properties of the nodes,
+	// grouped like in NodesPerMixin.
+	DataPerMixin map[string]int64
+	// PrimaryTypesPerDepth is the number of nodes grouped by their depth in
+	// the content tree and by their primary type. PrimaryTypesPerDepth groups
+	// the nodes in buckets of fixed size, where the size of each bucket is
+	// StatsTypeDepthBucketSize.
+	PrimaryTypesPerDepth map[int]map[string]int
+	// NodesPerNamespace is the number of nodes grouped by the namespace of
+	// their name, as returned by paths.QName.Namespace. The root node is not
+	// counted. Nodes whose name is unqualified or invalid are grouped under
+	// the empty string.
+	NodesPerNamespace map[string]int
+	// PropertiesPerNamespace is the number of properties grouped by the
+	// namespace of their name, like in NodesPerNamespace.
+	PropertiesPerNamespace map[string]int
+}
+
+// statsNode collects information about a node while its properties are read.
+type statsNode struct {
+	primaryType string
+	mixins      []string
+	data        int64
 }
 
 // Statistics parses a stream of command and extract statistics about the
 // export. Statistics either returns a non-nil Stats or an error.
 func Statistics(commands <-chan parser.Cmd) (*Stats, error) {
 	stats := Stats{
-		PropertiesPerType:  make(map[string]int),
-		PropertiesPerDepth: make(map[int]int),
-		NodesPerDepth:      make(map[int]int),
-		ValuesPerSize:      make(map[int]int),
+		PropertiesPerType:      make(map[string]int),
+		PropertiesPerDepth:     make(map[int]int),
+		NodesPerDepth:          make(map[int]int),
+		ValuesPerSize:          make(map[int]int),
+		NodesPerPrimaryType:    make(map[string]int),
+		DataPerPrimaryType:     make(map[string]int64),
+		NodesPerMixin:          make(map[string]int),
+		DataPerMixin:           make(map[string]int64),
+		PrimaryTypesPerDepth:   make(map[int]map[string]int),
+		NodesPerNamespace:      make(map[string]int),
+		PropertiesPerNamespace: make(map[string]int),
 	}
 
 	if err := stats.parse(commands); err != nil {
@@ -85,6 +136,8 @@ func (s *Stats) parseRoot(commands <-chan parser.Cmd) error {
 	s.Nodes++
 	s.NodesPerDepth[s.nodeDepthToBucket(0)]++
 
+	var node statsNode
+
 	for command := range commands {
 		switch cmd := command.(type) {
 		case parser.C:
@@ -92,10 +145,11 @@ func (s *Stats) parseRoot(commands <-chan parser.Cmd) error {
 				return err
 			}
 		case parser.P:
-			if err := s.parseProperty(cmd, 0, commands); err != nil {
+			if err := s.parseProperty(cmd, 0, &node, commands); err != nil {
 				return err
 			}
 		case parser.Up:
+			s.addNode(&node, 0)
 			return nil
 		case parser.Err:
 			return s.onError(cmd)
@@ -109,6 +163,9 @@ func (s *Stats) parseRoot(commands <-chan parser.Cmd) error {
 func (s *Stats) parseNode(c parser.C, depth int, commands <-chan parser.Cmd) error {
 	s.Nodes++
 	s.NodesPerDepth[s.nodeDepthToBucket(depth)]++
+	s.NodesPerNamespace[namespace(c.Name)]++
+
+	var node statsNode
 
 	for command := range commands {
 		switch cmd := command.(type) {
@@ -117,10 +174,11 @@ func (s *Stats) parseNode(c parser.C, depth int, commands <-chan parser.Cmd) err
 				return err
 			}
 		case parser.P:
-			if err := s.parseProperty(cmd, depth, commands); err != nil {
+			if err := s.parseProperty(cmd, depth, &node, commands); err != nil {
 				return err
 			}
 		case parser.Up:
+			s.addNode(&node, depth)
 			return nil
 		case parser.Err:
 			return s.onError(cmd)
@@ -131,10 +189,11 @@ func (s *Stats) parseNode(c parser.C, depth int, commands <-chan parser.Cmd) err
 	return nil
 }
 
-func (s *Stats) parseProperty(p parser.P, depth int, commands <-chan parser.Cmd) error {
+func (s *Stats) parseProperty(p parser.P, depth int, node *statsNode, commands <-chan parser.Cmd) error {
 	s.Properties++
 	s.PropertiesPerType[p.Type]++
 	s.PropertiesPerDepth[s.propertyDepthToBucket(depth)]++
+	s.PropertiesPerNamespace[namespace(p.Name)]++
 
 	for command := range commands {
 		switch cmd := command.(type) {
@@ -142,10 +201,18 @@ func (s *Stats) parseProperty(p parser.P, depth int, commands <-chan parser.Cmd)
 			size := len([]byte(cmd.Data))
 			s.Data += int64(size)
 			s.ValuesPerSize[s.valueSizeToBucket(size)]++
+			node.data += int64(size)
+			switch p.Name {
+			case statsPrimaryTypeProperty:
+				node.primaryType = cmd.Data
+			case statsMixinTypesProperty:
+				node.mixins = append(node.mixins, cmd.Data)
+			}
 		case parser.X:
 			size := base64.StdEncoding.DecodedLen(len(cmd.Data))
 			s.Data += int64(size)
 			s.ValuesPerSize[s.valueSizeToBucket(size)]++
+			node.data += int64(size)
 		case parser.Up:
 			return nil
 		case parser.Err:
@@ -157,6 +224,24 @@ func (s *Stats) parseProperty(p parser.P, depth int, commands <-chan parser.Cmd)
 	return nil
 }
 
+func (s *Stats) addNode(node *statsNode, depth int) {
+	s.NodesPerPrimaryType[node.primaryType]++
+	s.DataPerPrimaryType[node.primaryType] += node.data
+
+	for _, mixin := range node.mixins {
+		s.NodesPerMixin[mixin]++
+		s.DataPerMixin[mixin] += node.data
+	}
+
+	bucket := s.typeDepthToBucket(depth)
+
+	if s.PrimaryTypesPerDepth[bucket] == nil {
+		s.PrimaryTypesPerDepth[bucket] = make(map[string]int)
+	}
+
+	s.PrimaryTypesPerDepth[bucket][node.primaryType]++
+}
+
 func (s *Stats) onError(err parser.Err) error {
 	return fmt.Errorf("error at line %v: %v", err.Line, err.Err)
 }
@@ -165,6 +250,16 @@ func (s *Stats) onUnexpected(cmd parser.Cmd) error {
 	return fmt.Errorf("unexpected command %T", cmd)
 }
 
+// namespace returns the namespace of a name, or an empty string if the name is
+// invalid.
+func namespace(name string) string {
+	q, err := paths.ParseName(name)
+	if err != nil {
+		return ""
+	}
+	return q.Namespace()
+}
+
 func (*Stats) nodeDepthToBucket(depth int) int {
 	return (depth / StatsNodeDepthBucketSize) * StatsNodeDepthBucketSize
 }
@@ -173,6 +268,10 @@ func (*Stats) propertyDepthToBucket(depth int) int {
 	return (depth / StatsPropertyDepthBucketSize) * StatsPropertyDepthBucketSize
 }
 
+func (*Stats) typeDepthToBucket(depth int) int {
+	return (depth / StatsTypeDepthBucketSize) * StatsTypeDepthBucketSize
+}
+
 func (*Stats) valueSizeToBucket(size int) int {
 	bucket := 0
 	for size >= StatsValueSizeBucketScale {
@@ -0,0 +1,93 @@
+package transform
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+func TestStatisticsTypes(t *testing.T) {
+	cmds := parser.Parse(strings.NewReader(`
+		r
+		p Name jcr:primaryType
+		v rep:root
+		^
+		c a
+		p Name jcr:primaryType
+		v cq:Page
+		^
+		p Name jcr:mixinTypes
+		v mix:versionable
+		v mix:lockable
+		^
+		c b
+		p Name jcr:primaryType
+		v cq:Page
+		^
+		p String title
+		v abc
+		^
+		^
+		c c
+		^
+		^
+		^
+	`))
+
+	stats, err := Statistics(cmds)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	if expected := map[string]int{"rep:root": 1, "cq:Page": 2, "": 1}; !reflect.DeepEqual(stats.NodesPerPrimaryType, expected) {
+		t.Errorf("nodes per primary type: expected %v, got %v\n", expected, stats.NodesPerPrimaryType)
+	}
+	if expected := map[string]int64{"rep:root": 8, "cq:Page": 44, "": 0}; !reflect.DeepEqual(stats.DataPerPrimaryType, expected) {
+		t.Errorf("data per primary type: expected %v, got %v\n", expected, stats.DataPerPrimaryType)
+	}
+	if expected := map[string]int{"mix:versionable": 1, "mix:lockable": 1}; !reflect.DeepEqual(stats.NodesPerMixin, expected) {
+		t.Errorf("nodes per mixin: expected %v, got %v\n", expected, stats.NodesPerMixin)
+	}
+	if expected := map[string]int64{"mix:versionable": 34, "mix:lockable": 34}; !reflect.DeepEqual(stats.DataPerMixin, expected) {
+		t.Errorf("data per mixin: expected %v, got %v\n", expected, stats.DataPerMixin)
+	}
+	if expected := map[int]map[string]int{0: {"rep:root": 1, "cq:Page": 2, "": 1}}; !reflect.DeepEqual(stats.PrimaryTypesPerDepth, expected) {
+		t.Errorf("primary types per depth: expected %v, got %v\n", expected, stats.PrimaryTypesPerDepth)
+	}
+}
+
+func TestStatisticsNamespaces(t *testing.T) {
+	cmds := parser.Parse(strings.NewReader(`
+		r
+		p Name jcr:primaryType
+		v rep:root
+		^
+		c jcr:content
+		p String title
+		v abc
+		^
+		c {http://example.com}a
+		p String old:title
+		v abc
+		^
+		^
+		^
+		c b
+		^
+		^
+	`))
+
+	stats, err := Statistics(cmds)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	if expected := map[string]int{"jcr": 1, "{http://example.com}": 1, "": 1}; !reflect.DeepEqual(stats.NodesPerNamespace, expected) {
+		t.Errorf("nodes per namespace: expected %v, got %v\n", expected, stats.NodesPerNamespace)
+	}
+	if expected := map[string]int{"jcr": 1, "old": 1, "": 1}; !reflect.DeepEqual(stats.PropertiesPerNamespace, expected) {
+		t.Errorf("properties per namespace: expected %v, got %v\n", expected, stats.PropertiesPerNamespace)
+	}
+}
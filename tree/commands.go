@@ -0,0 +1,36 @@
+package tree
+
+import "github.com/francescomari/nu/parser"
+
+// Commands returns a stream of commands describing the tree rooted at the
+// node, which becomes the root of the stream. The properties of every node are
+// emitted before its children. The stream can be passed to
+// serializer.Serialize, or to any function consuming a stream of commands.
+func (n *Node) Commands() <-chan parser.Cmd {
+	ch := make(chan parser.Cmd)
+	go func() {
+		defer close(ch)
+		ch <- parser.R{}
+		n.emit(ch)
+	}()
+	return ch
+}
+
+func (n *Node) emit(ch chan<- parser.Cmd) {
+	for _, p := range n.properties {
+		ch <- parser.P{Type: p.Type, Name: p.Name}
+		for _, v := range p.Values {
+			if v.Binary {
+				ch <- parser.X{Data: v.Data}
+			} else {
+				ch <- parser.V{Data: v.Data}
+			}
+		}
+		ch <- parser.Up{}
+	}
+	for _, c := range n.children {
+		ch <- parser.C{Name: c.Name}
+		c.emit(ch)
+	}
+	ch <- parser.Up{}
+}
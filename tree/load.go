@@ -0,0 +1,88 @@
+package tree
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/francescomari/nu/parser"
+)
+
+var (
+	// ErrNoRoot is returned by Load if the stream doesn't contain a root.
+	ErrNoRoot = errors.New("no root")
+)
+
+// Load reads a stream of commands and builds the tree described by it. Load
+// either returns the root of the tree or an error.
+func Load(commands <-chan parser.Cmd) (*Node, error) {
+	var root *Node
+
+	for command := range commands {
+		switch cmd := command.(type) {
+		case parser.R:
+			if root != nil {
+				return nil, fmt.Errorf("unexpected command %T", cmd)
+			}
+			root = New()
+			if err := loadNode(root, commands); err != nil {
+				return nil, err
+			}
+		case parser.Err:
+			return nil, onError(cmd)
+		default:
+			return nil, fmt.Errorf("unexpected command %T", cmd)
+		}
+	}
+
+	if root == nil {
+		return nil, ErrNoRoot
+	}
+
+	return root, nil
+}
+
+func loadNode(n *Node, commands <-chan parser.Cmd) error {
+	for command := range commands {
+		switch cmd := command.(type) {
+		case parser.C:
+			if err := loadNode(n.AddChild(cmd.Name), commands); err != nil {
+				return err
+			}
+		case parser.P:
+			p := &Property{Name: cmd.Name, Type: cmd.Type}
+			if err := loadProperty(p, commands); err != nil {
+				return err
+			}
+			n.properties = append(n.properties, p)
+		case parser.Up:
+			return nil
+		case parser.Err:
+			return onError(cmd)
+		default:
+			return fmt.Errorf("unexpected command %T", cmd)
+		}
+	}
+	return fmt.Errorf("unterminated node %v", n.Path())
+}
+
+func loadProperty(p *Property, commands <-chan parser.Cmd) error {
+	for command := range commands {
+		switch cmd := command.(type) {
+		case parser.V:
+			p.Values = append(p.Values, Value{Data: cmd.Data})
+		case parser.X:
+			p.Values = append(p.Values, Value{Data: cmd.Data, Binary: true})
+		case parser.Up:
+			return nil
+		case parser.Err:
+			return onError(cmd)
+		default:
+			return fmt.Errorf("unexpected command %T", cmd)
+		}
+	}
+	return fmt.Errorf("unterminated property %v", p.Name)
+}
+
+func onError(err parser.Err) error {
+	return fmt.Errorf("error at line %v: %v", err.Line, err.Err)
+}
@@ -0,0 +1,95 @@
+package tree
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+	"github.com/francescomari/nu/serializer"
+)
+
+const loadExport = `r
+p Name jcr:primaryType
+v rep:root
+^
+c a
+p String title
+v hello
+v world
+^
+p Binary data
+x cafe
+^
+c b
+^
+c b
+p Long n
+v 1
+^
+^
+^
+c c
+^
+^
+`
+
+func TestLoad(t *testing.T) {
+	root, err := Load(parser.Parse(strings.NewReader(loadExport)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	if n := len(root.Children()); n != 2 {
+		t.Errorf("children of root: expected 2, got %v\n", n)
+	}
+
+	a := root.Child("a")
+	if a == nil {
+		t.Fatalf("child a: expected a node, got nil\n")
+	}
+	if p := a.Property("title"); p == nil || len(p.Values) != 2 || p.Values[1].Data != "world" {
+		t.Errorf("property title: unexpected value %+v\n", p)
+	}
+	if p := a.Property("data"); p == nil || len(p.Values) != 1 || !p.Values[0].Binary || p.Values[0].Data != "cafe" {
+		t.Errorf("property data: unexpected value %+v\n", p)
+	}
+	if n := len(a.Children()); n != 2 {
+		t.Errorf("children of a: expected 2, got %v\n", n)
+	}
+}
+
+func TestLoadRoundTrip(t *testing.T) {
+	root, err := Load(parser.Parse(strings.NewReader(loadExport)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	var b bytes.Buffer
+
+	if err := serializer.Serialize(root.Commands(), &b); err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	if b.String() != loadExport {
+		t.Errorf("expected %q, got %q\n", loadExport, b.String())
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		export string
+	}{
+		{"empty", ""},
+		{"invalid", "r\nfoo\n"},
+		{"unterminated", "r\nc a\n^\n"},
+		{"two roots", "r\n^\nr\n^\n"},
+	}
+
+	for _, tt := range tests {
+		if _, err := Load(parser.Parse(strings.NewReader(tt.export))); err == nil {
+			t.Errorf("%v: expected error, got nil\n", tt.name)
+		}
+	}
+}
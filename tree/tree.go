@@ -0,0 +1,236 @@
+// Package tree implements an in-memory model of the content of an export.
+//
+// Unlike the streaming API of the other packages, a tree gives random access
+// to its nodes and properties, and can be modified. A tree is loaded from a
+// stream of commands with Load, and turned back into a stream of commands with
+// Node.Commands, which can be passed to serializer.Serialize. Since the whole
+// content is kept in memory, trees are suitable for small exports only.
+package tree
+
+import (
+	"errors"
+
+	"github.com/francescomari/nu/paths"
+)
+
+var (
+	// ErrNotFound is returned when a node doesn't exist.
+	ErrNotFound = errors.New("node not found")
+
+	// SkipChildren is returned by the function passed to Walk to skip the
+	// descendants of a node.
+	SkipChildren = errors.New("skip children")
+)
+
+// Node is a node of a content tree. The zero value is not usable, use New to
+// create a root node, or AddChild to create a child node.
+type Node struct {
+	// Name is the name of the node. The name of the root is empty.
+	Name string
+
+	parent     *Node
+	properties []*Property
+	children   []*Node
+}
+
+// Property is a property of a node.
+type Property struct {
+	Name   string
+	Type   string
+	Values []Value
+}
+
+// Value is a value of a property.
+type Value struct {
+	// Data is the value. If the value is binary, Data is encoded in
+	// hexadecimal, like in the X command.
+	Data   string
+	Binary bool
+}
+
+// New creates a root node without properties and children.
+func New() *Node {
+	return &Node{}
+}
+
+// Parent returns the parent of the node, or nil if the node is the root of its
+// tree.
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+// Root returns the root of the tree containing the node.
+func (n *Node) Root() *Node {
+	for n.parent != nil {
+		n = n.parent
+	}
+	return n
+}
+
+// Children returns the children of the node, in order. The returned slice
+// must not be modified.
+func (n *Node) Children() []*Node {
+	return n.children
+}
+
+// Properties returns the properties of the node, in order. The returned slice
+// must not be modified.
+func (n *Node) Properties() []*Property {
+	return n.properties
+}
+
+// Child returns the first child with the specified name, or nil if no such
+// child exists.
+func (n *Node) Child(name string) *Node {
+	return n.child(paths.Segment{Name: name, Index: 1})
+}
+
+func (n *Node) child(s paths.Segment) *Node {
+	index := 0
+	for _, c := range n.children {
+		if c.Name != s.Name {
+			continue
+		}
+		if index++; index == s.Index {
+			return c
+		}
+	}
+	return nil
+}
+
+// Property returns the property with the specified name, or nil if no such
+// property exists.
+func (n *Node) Property(name string) *Property {
+	for _, p := range n.properties {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Index returns the position of the node among its siblings with the same
+// name, starting from 1. The index of the root is 1.
+func (n *Node) Index() int {
+	if n.parent == nil {
+		return 1
+	}
+	index := 0
+	for _, c := range n.parent.children {
+		if c.Name == n.Name {
+			index++
+		}
+		if c == n {
+			break
+		}
+	}
+	return index
+}
+
+// Path returns the path of the node from the root of its tree. Same-name
+// siblings are addressed by their index.
+func (n *Node) Path() paths.Path {
+	if n.parent == nil {
+		return paths.Root
+	}
+	return n.parent.Path().Child(n.Name, n.Index())
+}
+
+// Lookup returns the node at path. If path is relative, it is resolved
+// relative to the node. If path is absolute, it is resolved from the root of
+// the tree. If the node doesn't exist, Lookup returns ErrNotFound.
+func (n *Node) Lookup(path string) (*Node, error) {
+	p, err := n.Path().Resolve(path)
+	if err != nil {
+		return nil, err
+	}
+
+	node := n.Root()
+
+	for _, s := range p {
+		if node = node.child(s); node == nil {
+			return nil, ErrNotFound
+		}
+	}
+
+	return node, nil
+}
+
+// Walk calls fn for the node and every descendant of the node, in depth-first
+// order, with every node visited before its children. If fn returns
+// SkipChildren, the descendants of the node are not visited. If fn returns any
+// other error, Walk stops and returns that error.
+func (n *Node) Walk(fn func(*Node) error) error {
+	if err := fn(n); err == SkipChildren {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, c := range n.children {
+		if err := c.Walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddChild adds a new child with the specified name after the existing
+// children, and returns it. If a child with the same name exists, the new
+// child is a same-name sibling.
+func (n *Node) AddChild(name string) *Node {
+	c := &Node{Name: name, parent: n}
+	n.children = append(n.children, c)
+	return c
+}
+
+// Remove removes the node and its descendants from the tree. After Remove, the
+// node is the root of a separate tree. Removing the root has no effect.
+func (n *Node) Remove() {
+	if n.parent == nil {
+		return
+	}
+
+	siblings := n.parent.children
+
+	for i, c := range siblings {
+		if c == n {
+			n.parent.children = append(siblings[:i:i], siblings[i+1:]...)
+			break
+		}
+	}
+
+	n.parent = nil
+}
+
+// SetProperty sets the text values of a property, and returns the property. If
+// the property exists, its type and values are replaced. Otherwise, the
+// property is added after the existing properties.
+func (n *Node) SetProperty(name, typ string, values ...string) *Property {
+	p := n.Property(name)
+
+	if p == nil {
+		p = &Property{Name: name}
+		n.properties = append(n.properties, p)
+	}
+
+	p.Type = typ
+	p.Values = make([]Value, len(values))
+
+	for i, v := range values {
+		p.Values[i] = Value{Data: v}
+	}
+
+	return p
+}
+
+// RemoveProperty removes the property with the specified name, and tells if
+// the property existed.
+func (n *Node) RemoveProperty(name string) bool {
+	for i, p := range n.properties {
+		if p.Name == name {
+			n.properties = append(n.properties[:i:i], n.properties[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package tree
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/francescomari/nu/parser"
+)
+
+func newTestTree() *Node {
+	root := New()
+	root.SetProperty("jcr:primaryType", "Name", "rep:root")
+	a := root.AddChild("a")
+	a.AddChild("b").SetProperty("n", "Long", "1")
+	a.AddChild("b").SetProperty("n", "Long", "2")
+	a.AddChild("c")
+	root.AddChild("d")
+	return root
+}
+
+func TestPath(t *testing.T) {
+	root := newTestTree()
+
+	var visited []string
+
+	root.Walk(func(n *Node) error {
+		visited = append(visited, n.Path().String())
+		return nil
+	})
+
+	expected := []string{"/", "/a", "/a/b", "/a/b[2]", "/a/c", "/d"}
+
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expected %v, got %v\n", expected, visited)
+	}
+}
+
+func TestWalkSkipChildren(t *testing.T) {
+	root := newTestTree()
+
+	var visited []string
+
+	root.Walk(func(n *Node) error {
+		visited = append(visited, n.Path().String())
+		if n.Name == "a" {
+			return SkipChildren
+		}
+		return nil
+	})
+
+	expected := []string{"/", "/a", "/d"}
+
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expected %v, got %v\n", expected, visited)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	root := newTestTree()
+	a := root.Child("a")
+
+	tests := []struct {
+		from *Node
+		path string
+		n    string
+	}{
+		{root, "/", ""},
+		{root, "/a/b[2]", "2"},
+		{root, "a/b", "1"},
+		{a, "b[2]", "2"},
+		{a, "../a/./b", "1"},
+		{a.Child("c"), "/a/b[1]", "1"},
+	}
+
+	for _, tt := range tests {
+		n, err := tt.from.Lookup(tt.path)
+		if err != nil {
+			t.Errorf("path %v: unexpected error: %v\n", tt.path, err)
+			continue
+		}
+		var value string
+		if p := n.Property("n"); p != nil {
+			value = p.Values[0].Data
+		}
+		if value != tt.n {
+			t.Errorf("path %v: expected node with n=%q, got %q\n", tt.path, tt.n, value)
+		}
+	}
+
+	for _, path := range []string{"/x", "/a/b[3]", "d"} {
+		if _, err := a.Lookup(path); err != ErrNotFound {
+			t.Errorf("path %v: expected ErrNotFound, got %v\n", path, err)
+		}
+	}
+
+	if _, err := root.Lookup("/a[0]"); err == nil {
+		t.Errorf("invalid path: expected error, got nil\n")
+	}
+}
+
+func TestMutations(t *testing.T) {
+	root := newTestTree()
+
+	b, _ := root.Lookup("/a/b")
+	b.Remove()
+
+	if b.Parent() != nil || b.Root() != b {
+		t.Errorf("removed node: expected a separate tree\n")
+	}
+	if n, _ := root.Lookup("/a/b"); n == nil || n.Property("n").Values[0].Data != "2" {
+		t.Errorf("removed node: expected /a/b to be the former /a/b[2]\n")
+	}
+
+	root.SetProperty("jcr:primaryType", "Name", "nt:unstructured")
+	root.SetProperty("tags", "String", "x", "y")
+
+	if !root.RemoveProperty("tags") || root.RemoveProperty("tags") {
+		t.Errorf("remove property: unexpected result\n")
+	}
+
+	d := root.Child("d")
+	d.Name = "e"
+	d.SetProperty("data", "Binary").Values = []Value{{Data: "cafe", Binary: true}}
+
+	var cmds []parser.Cmd
+	for cmd := range root.Commands() {
+		cmds = append(cmds, cmd)
+	}
+
+	expected := []parser.Cmd{
+		parser.R{},
+		parser.P{Type: "Name", Name: "jcr:primaryType"},
+		parser.V{Data: "nt:unstructured"},
+		parser.Up{},
+		parser.C{Name: "a"},
+		parser.C{Name: "b"},
+		parser.P{Type: "Long", Name: "n"},
+		parser.V{Data: "2"},
+		parser.Up{},
+		parser.Up{},
+		parser.C{Name: "c"},
+		parser.Up{},
+		parser.Up{},
+		parser.C{Name: "e"},
+		parser.P{Type: "Binary", Name: "data"},
+		parser.X{Data: "cafe"},
+		parser.Up{},
+		parser.Up{},
+		parser.Up{},
+	}
+
+	if !reflect.DeepEqual(cmds, expected) {
+		t.Errorf("expected %v, got %v\n", expected, cmds)
+	}
+}